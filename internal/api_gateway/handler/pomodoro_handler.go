@@ -9,8 +9,11 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	// authmw "github.com/latrung124/Totodoro-Backend/internal/api_gateway/authentication/middleware"
@@ -18,8 +21,12 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// sseKeepAlive is how often an idle event stream sends a comment to keep proxies from closing it.
+const sseKeepAlive = 15 * time.Second
+
 type PomodoroHandler struct {
-	client pomodoropb.PomodoroServiceClient
+	client    pomodoropb.PomodoroServiceClient
+	marshaler runtime.Marshaler
 }
 
 func NewPomodoroHandler(client pomodoropb.PomodoroServiceClient) *PomodoroHandler {
@@ -38,6 +45,7 @@ func RegisterPomodoroRoutes(mux *http.ServeMux, h *PomodoroHandler) {
 			DiscardUnknown: true,
 		},
 	}
+	h.marshaler = jsonpb
 
 	gwmux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonpb),
@@ -50,4 +58,75 @@ func RegisterPomodoroRoutes(mux *http.ServeMux, h *PomodoroHandler) {
 
 	mux.Handle("/v1/sessions", gwmux)
 	mux.Handle("/v1/sessions/", gwmux)
+	mux.Handle("/v1/reflections/", gwmux)
+
+	// Server-Sent Events bridge for the WatchSessions stream
+	mux.HandleFunc("GET /v1/sessions/users/{user_id}/events", h.watchSessions)
+}
+
+// watchSessions relays WatchSessions as a text/event-stream until the client disconnects.
+func (h *PomodoroHandler) watchSessions(w http.ResponseWriter, r *http.Request) {
+	stream, err := h.client.WatchSessions(r.Context(), &pomodoropb.WatchSessionsRequest{UserId: r.PathValue("user_id")})
+	if err != nil {
+		log.Printf("[gateway][pomodoro] failed to open session stream: %v", err)
+		http.Error(w, "failed to watch sessions", http.StatusBadGateway)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's WriteTimeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("[gateway][pomodoro] cannot clear write deadline: %v", err)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	events := make(chan *pomodoropb.SessionEvent)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case err := <-errc:
+			if r.Context().Err() == nil {
+				log.Printf("[gateway][pomodoro] session stream ended: %v", err)
+				fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+				_ = rc.Flush()
+			}
+			return
+		case event := <-events:
+			data, err := h.marshaler.Marshal(event)
+			if err != nil {
+				log.Printf("[gateway][pomodoro] failed to marshal session event: %v", err)
+				continue
+			}
+			name := strings.ToLower(strings.TrimPrefix(event.GetType().String(), "SESSION_EVENT_TYPE_"))
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+			_ = rc.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			_ = rc.Flush()
+		}
+	}
 }
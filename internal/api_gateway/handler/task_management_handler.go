@@ -9,12 +9,17 @@ package handler
 
 import (
 	"context"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	// authmw "github.com/latrung124/Totodoro-Backend/internal/api_gateway/authentication/middleware"
 	taskpb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -49,6 +54,69 @@ func RegisterTaskManagementRoutes(mux *http.ServeMux, h *TaskManagementHandler)
 	}
 
 	mux.Handle("/v1/tasks/", gwmux)
+	mux.Handle("/v1/tasks:batchMove", gwmux)
+	mux.Handle("/v1/tasks:batchUpdate", gwmux)
 	mux.Handle("/v1/task-groups", gwmux)
 	mux.Handle("/v1/task-groups/", gwmux)
+	mux.Handle("/v1/task-templates/", gwmux)
+	mux.Handle("/v1/tags", gwmux)
+	mux.Handle("/v1/tags/", gwmux)
+
+	// File download bridge for the ExportTasks stream
+	mux.HandleFunc("GET /v1/tasks/users/{user_id}/export", h.exportTasks)
+}
+
+// exportFormats maps the format query parameter of an export onto ExportFormat.
+var exportFormats = map[string]taskpb.ExportFormat{
+	"":     taskpb.ExportFormat_EXPORT_FORMAT_JSON,
+	"json": taskpb.ExportFormat_EXPORT_FORMAT_JSON,
+	"csv":  taskpb.ExportFormat_EXPORT_FORMAT_CSV,
+	"ics":  taskpb.ExportFormat_EXPORT_FORMAT_ICS,
+}
+
+// exportTasks relays the ExportTasks stream as a file download.
+func (h *TaskManagementHandler) exportTasks(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormats[strings.ToLower(r.URL.Query().Get("format"))]
+	if !ok {
+		http.Error(w, "format must be json, csv or ics", http.StatusBadRequest)
+		return
+	}
+
+	stream, err := h.client.ExportTasks(r.Context(), &taskpb.ExportTasksRequest{UserId: r.PathValue("user_id"), Format: format})
+	if err != nil {
+		log.Printf("[gateway][task] failed to open export stream: %v", err)
+		http.Error(w, "failed to export tasks", http.StatusBadGateway)
+		return
+	}
+	// Errors such as an invalid user_id arrive with the first message.
+	chunk, err := stream.Recv()
+	if err != nil {
+		st := status.Convert(err)
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// A large export can outlive the server's WriteTimeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("[gateway][task] cannot clear write deadline: %v", err)
+	}
+	w.Header().Set("Content-Type", chunk.GetContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": chunk.GetFilename()}))
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		if _, err := w.Write(chunk.GetData()); err != nil {
+			return
+		}
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// The status line is gone; cut the response short so the client sees a failed download.
+			log.Printf("[gateway][task] export stream ended: %v", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
 }
@@ -10,6 +10,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -29,8 +31,25 @@ type Config struct {
 	StatisticPort        string
 	NotificationPort     string
 	TaskPort             string
+
+	// Stale session reaper: running or paused sessions whose last_update is more than
+	// StaleSessionThreshold ago are completed if their timer ran out and abandoned
+	// otherwise; the check runs every StaleSessionReapInterval.
+	StaleSessionThreshold    time.Duration
+	StaleSessionReapInterval time.Duration
+
+	// Trashed tasks and task groups are purged permanently after TrashRetention,
+	// set in whole days with TRASH_RETENTION_DAYS.
+	TrashRetention time.Duration
 }
 
+// Defaults used when the corresponding environment variables are unset.
+const (
+	defaultStaleSessionThreshold    = 2 * time.Hour
+	defaultStaleSessionReapInterval = 5 * time.Minute
+	defaultTrashRetentionDays       = 30
+)
+
 func Load() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
@@ -57,5 +76,39 @@ func GetConfig() (*Config, error) {
 		StatisticPort:        os.Getenv("STATISTIC_PORT"),
 		NotificationPort:     os.Getenv("NOTIFICATION_PORT"),
 		TaskPort:             os.Getenv("TASK_PORT"),
+
+		StaleSessionThreshold:    getDuration("STALE_SESSION_THRESHOLD", defaultStaleSessionThreshold),
+		StaleSessionReapInterval: getDuration("STALE_SESSION_REAP_INTERVAL", defaultStaleSessionReapInterval),
+		TrashRetention:           getDays("TRASH_RETENTION_DAYS", defaultTrashRetentionDays),
 	}, nil
 }
+
+// getDuration reads a Go duration (e.g. "90m", "2h") from the environment,
+// falling back to def when the variable is unset or invalid.
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, v, def)
+		return def
+	}
+	return d
+}
+
+// getDays reads a whole number of days (e.g. "30") from the environment,
+// falling back to def days when the variable is unset or invalid.
+func getDays(key string, def int) time.Duration {
+	days := def
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Printf("Invalid %s=%q, using default %d", key, v, def)
+		} else {
+			days = n
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
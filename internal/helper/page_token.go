@@ -0,0 +1,71 @@
+/*
+File: internal/helper/page_token.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/helper
+Description: Opaque page tokens for keyset (cursor) pagination.
+*/
+
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrPageTokenOrdering is returned when a page token was issued for another sort order.
+var ErrPageTokenOrdering = errors.New("page token was issued for a different sort order")
+
+// pageToken is the decoded form of a page token: the ordering it was issued for and
+// the sort key of the last row of the page.
+type pageToken struct {
+	Ordering string   `json:"o"`
+	Keys     []string `json:"k"`
+}
+
+// PageOrdering names a sort order for EncodePageToken, e.g. "deadline desc".
+func PageOrdering(sortKey string, desc bool) string {
+	if desc {
+		return sortKey + " desc"
+	}
+	return sortKey + " asc"
+}
+
+// EncodePageToken packs the sort key of the last row of a page, and the ordering
+// it was read in, into an opaque token.
+func EncodePageToken(ordering string, keys ...string) string {
+	b, _ := json.Marshal(pageToken{Ordering: ordering, Keys: keys})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageToken unpacks a token produced by EncodePageToken holding exactly n keys.
+// A token issued for another ordering returns ErrPageTokenOrdering.
+func DecodePageToken(token, ordering string, n int) ([]string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil || len(t.Keys) != n {
+		return nil, ErrInvalidPageToken
+	}
+	if t.Ordering != ordering {
+		return nil, ErrPageTokenOrdering
+	}
+	return t.Keys, nil
+}
+
+// ClampPageSize applies the default to a zero or negative page size and caps it at max.
+func ClampPageSize(size, def, max int32) int32 {
+	if size <= 0 {
+		return def
+	}
+	if size > max {
+		return max
+	}
+	return size
+}
@@ -0,0 +1,50 @@
+/*
+File: internal/helper/page_token_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for page tokens and page size clamping.
+*/
+
+package helper
+
+import "testing"
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	ordering := PageOrdering("start_time", true)
+	token := EncodePageToken(ordering, "2026-10-16T08:00:00.123456Z", "7b0c1a52-6f3e-4c1e-9a49-1f0f0d1f2a3b")
+
+	keys, err := DecodePageToken(token, ordering, 2)
+	if err != nil {
+		t.Fatalf("DecodePageToken failed: %v", err)
+	}
+	if keys[0] != "2026-10-16T08:00:00.123456Z" || keys[1] != "7b0c1a52-6f3e-4c1e-9a49-1f0f0d1f2a3b" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	if _, err := DecodePageToken(token, ordering, 3); err != ErrInvalidPageToken {
+		t.Errorf("Expected ErrInvalidPageToken for wrong key count, got %v", err)
+	}
+	if _, err := DecodePageToken("not a token!", ordering, 2); err != ErrInvalidPageToken {
+		t.Errorf("Expected ErrInvalidPageToken for garbage, got %v", err)
+	}
+}
+
+func TestPageTokenRejectsOtherOrdering(t *testing.T) {
+	token := EncodePageToken(PageOrdering("deadline", false), "2026-10-16T08:00:00Z", "id")
+
+	if _, err := DecodePageToken(token, PageOrdering("deadline", true), 2); err != ErrPageTokenOrdering {
+		t.Errorf("Expected ErrPageTokenOrdering for another direction, got %v", err)
+	}
+	if _, err := DecodePageToken(token, PageOrdering("priority", false), 2); err != ErrPageTokenOrdering {
+		t.Errorf("Expected ErrPageTokenOrdering for another sort key, got %v", err)
+	}
+}
+
+func TestClampPageSize(t *testing.T) {
+	cases := []struct{ in, want int32 }{{0, 50}, {-1, 50}, {10, 10}, {500, 200}}
+	for _, c := range cases {
+		if got := ClampPageSize(c.in, 50, 200); got != c.want {
+			t.Errorf("ClampPageSize(%d) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ func SessionStatusDbEnumToString(status pomodoroPb.SessionStatus) string {
 		return "pending"
 	case pomodoroPb.SessionStatus_SESSION_STATUS_COMPLETED:
 		return "completed"
+	case pomodoroPb.SessionStatus_SESSION_STATUS_ABANDONED:
+		return "abandoned"
 	default:
 		return "idle"
 	}
@@ -37,6 +39,8 @@ func SessionStatusDbStringToEnum(status string) pomodoroPb.SessionStatus {
 		return pomodoroPb.SessionStatus_SESSION_STATUS_PENDING
 	case "completed":
 		return pomodoroPb.SessionStatus_SESSION_STATUS_COMPLETED
+	case "abandoned":
+		return pomodoroPb.SessionStatus_SESSION_STATUS_ABANDONED
 	default:
 		return pomodoroPb.SessionStatus_SESSION_STATUS_IDLE
 	}
@@ -48,6 +52,8 @@ func SessionTypeDbEnumToString(sessionType pomodoroPb.SessionType) string {
 		return "short break"
 	case pomodoroPb.SessionType_SESSION_TYPE_LONG_BREAK:
 		return "long break"
+	case pomodoroPb.SessionType_SESSION_TYPE_FOCUS:
+		return "focus"
 	default:
 		return "short break"
 	}
@@ -59,7 +65,31 @@ func SessionTypeDbStringToEnum(sessionType string) pomodoroPb.SessionType {
 		return pomodoroPb.SessionType_SESSION_TYPE_SHORT_BREAK
 	case "long break":
 		return pomodoroPb.SessionType_SESSION_TYPE_LONG_BREAK
+	case "focus":
+		return pomodoroPb.SessionType_SESSION_TYPE_FOCUS
 	default:
 		return pomodoroPb.SessionType_SESSION_TYPE_SHORT_BREAK
 	}
 }
+
+func InterruptionKindDbEnumToString(kind pomodoroPb.InterruptionKind) string {
+	switch kind {
+	case pomodoroPb.InterruptionKind_INTERRUPTION_KIND_INTERNAL:
+		return "internal"
+	case pomodoroPb.InterruptionKind_INTERRUPTION_KIND_EXTERNAL:
+		return "external"
+	default:
+		return "internal"
+	}
+}
+
+func InterruptionKindDbStringToEnum(kind string) pomodoroPb.InterruptionKind {
+	switch kind {
+	case "internal":
+		return pomodoroPb.InterruptionKind_INTERRUPTION_KIND_INTERNAL
+	case "external":
+		return pomodoroPb.InterruptionKind_INTERRUPTION_KIND_EXTERNAL
+	default:
+		return pomodoroPb.InterruptionKind_INTERRUPTION_KIND_INTERNAL
+	}
+}
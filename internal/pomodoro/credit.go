@@ -0,0 +1,102 @@
+/*
+File: internal/pomodoro/credit.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Crediting completed focus sessions to the linked task.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+)
+
+// creditRetryBatch caps how many uncredited sessions one retry pass handles.
+const creditRetryBatch = 100
+
+// TaskCreditor credits a completed focus session to its task. Implementations must be
+// idempotent per session. *task_management.Service satisfies it.
+type TaskCreditor interface {
+	CreditPomodoro(ctx context.Context, sessionID, taskID string, focused time.Duration) error
+}
+
+// creditTask credits a completed focus session and marks it as credited.
+// Tasks live in TaskDB and sessions in PomodoroDB, so the two writes cannot share a
+// transaction: the creditor is idempotent, and sessions left without task_credited_at
+// are picked up again by RunTaskCreditRetries.
+func (s *Service) creditTask(ctx context.Context, session *pb.PomodoroSession) {
+	if s.tasks == nil ||
+		session.SessionType != pb.SessionType_SESSION_TYPE_FOCUS ||
+		session.Status != pb.SessionStatus_SESSION_STATUS_COMPLETED {
+		return
+	}
+
+	// A completed session's progress is its focused time, pauses excluded.
+	focused := time.Duration(session.Progress) * time.Second
+	if err := s.tasks.CreditPomodoro(ctx, session.SessionId, session.TaskId, focused); err != nil {
+		log.Printf("Failed to credit session %s to task %s (will retry): %v", session.SessionId, session.TaskId, err)
+		return
+	}
+	if _, err := s.db.PomodoroDB.ExecContext(ctx,
+		`UPDATE sessions SET task_credited_at = $1 WHERE session_id = $2 AND task_credited_at IS NULL`,
+		time.Now(), session.SessionId); err != nil {
+		log.Printf("Failed to mark session %s as credited (will retry): %v", session.SessionId, err)
+	}
+}
+
+// RunTaskCreditRetries periodically credits completed focus sessions whose
+// credit did not go through, until ctx is cancelled.
+func (s *Service) RunTaskCreditRetries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryTaskCredits(ctx)
+		}
+	}
+}
+
+// retryTaskCredits runs one pass over uncredited completed focus sessions.
+func (s *Service) retryTaskCredits(ctx context.Context) {
+	rows, err := s.db.PomodoroDB.QueryContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE status = $1 AND session_type = $2 AND task_credited_at IS NULL
+		ORDER BY last_update
+		LIMIT $3`,
+		helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_COMPLETED),
+		helper.SessionTypeDbEnumToString(pb.SessionType_SESSION_TYPE_FOCUS),
+		creditRetryBatch,
+	)
+	if err != nil {
+		log.Printf("Failed to query uncredited sessions: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var pending []*pb.PomodoroSession
+	for rows.Next() {
+		session, _, err := scanSession(rows, now)
+		if err != nil {
+			log.Printf("Failed to scan uncredited session: %v", err)
+			rows.Close()
+			return
+		}
+		pending = append(pending, session)
+	}
+	rows.Close()
+
+	for _, session := range pending {
+		s.creditTask(ctx, session)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+File: internal/pomodoro/cycle.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Pomodoro cycle progression (focus -> short break ... -> long break).
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// nextInCycle decides the type and number_in_cycle of the session that follows a finished one.
+// A break keeps the number of the focus session it follows; after a long break the cycle restarts at 1.
+func nextInCycle(lastType pb.SessionType, lastNumber, interval int32) (pb.SessionType, int32) {
+	if interval <= 0 {
+		interval = defaultSettings.pomodoroInterval
+	}
+	if lastNumber <= 0 {
+		lastNumber = 1
+	}
+
+	switch lastType {
+	case pb.SessionType_SESSION_TYPE_LONG_BREAK:
+		return pb.SessionType_SESSION_TYPE_FOCUS, 1
+	case pb.SessionType_SESSION_TYPE_SHORT_BREAK:
+		return pb.SessionType_SESSION_TYPE_FOCUS, lastNumber + 1
+	default:
+		if lastNumber >= interval {
+			return pb.SessionType_SESSION_TYPE_LONG_BREAK, lastNumber
+		}
+		return pb.SessionType_SESSION_TYPE_SHORT_BREAK, lastNumber
+	}
+}
+
+// NextSession creates the session that follows a completed one, using the user's
+// pomodoro_interval and auto-start settings. Calling it again for the same session
+// (e.g. from a second device) returns the session created the first time.
+func (s *Service) NextSession(ctx context.Context, req *pb.NextSessionRequest) (*pb.NextSessionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin next session: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create next session")
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	last, lastTimer, err := getSession(ctx, tx, req.SessionId, true, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		log.Printf("Failed to retrieve session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve session")
+	}
+	if lastTimer.status != pb.SessionStatus_SESSION_STATUS_COMPLETED {
+		return nil, status.Errorf(codes.FailedPrecondition, "session %s is %s, not completed", req.SessionId, lastTimer.status)
+	}
+
+	existing, _, err := scanSession(tx.QueryRowContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE previous_session_id = $1`, req.SessionId), now)
+	switch {
+	case err == nil:
+		return &pb.NextSessionResponse{
+			Session:     existing,
+			AutoStarted: existing.Status != pb.SessionStatus_SESSION_STATUS_IDLE,
+		}, nil
+	case err != sql.ErrNoRows:
+		log.Printf("Failed to look up session following %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to create next session")
+	}
+
+	settings, err := s.loadSettings(ctx, last.UserId)
+	if err != nil {
+		log.Printf("Failed to load settings for user %s: %v", last.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to load user settings")
+	}
+
+	nextType, numberInCycle := nextInCycle(last.SessionType, last.NumberInCycle, settings.pomodoroInterval)
+	autoStart := settings.autoStartFor(nextType)
+	nextStatus := pb.SessionStatus_SESSION_STATUS_IDLE
+	if autoStart {
+		nextStatus = pb.SessionStatus_SESSION_STATUS_IN_PROGRESS
+	}
+
+	sessionID := uuid.NewString()
+	err = insertSession(ctx, tx, newSession{
+		sessionID:         sessionID,
+		userID:            last.UserId,
+		taskID:            last.TaskId,
+		sessionType:       nextType,
+		numberInCycle:     numberInCycle,
+		status:            nextStatus,
+		start:             now,
+		duration:          settings.durationFor(nextType),
+		previousSessionID: last.SessionId,
+	})
+	if err != nil {
+		log.Printf("Failed to create session following %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to create next session")
+	}
+
+	session, _, err := getSession(ctx, tx, sessionID, false, now)
+	if err != nil {
+		log.Printf("Failed to retrieve created session %s: %v", sessionID, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve created session")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit next session: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create next session")
+	}
+
+	log.Printf("Next session created: %s (%s #%d, after %s)", sessionID, nextType, numberInCycle, req.SessionId)
+	s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_CREATED, session)
+	return &pb.NextSessionResponse{Session: session, AutoStarted: autoStart}, nil
+}
@@ -0,0 +1,38 @@
+/*
+File: internal/pomodoro/cycle_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for pomodoro cycle progression.
+*/
+
+package pomodoro
+
+import (
+	"testing"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+)
+
+func TestNextInCycle(t *testing.T) {
+	cases := []struct {
+		name       string
+		lastType   pb.SessionType
+		lastNumber int32
+		interval   int32
+		wantType   pb.SessionType
+		wantNumber int32
+	}{
+		{"focus before interval", pb.SessionType_SESSION_TYPE_FOCUS, 1, 4, pb.SessionType_SESSION_TYPE_SHORT_BREAK, 1},
+		{"focus at interval", pb.SessionType_SESSION_TYPE_FOCUS, 4, 4, pb.SessionType_SESSION_TYPE_LONG_BREAK, 4},
+		{"short break", pb.SessionType_SESSION_TYPE_SHORT_BREAK, 2, 4, pb.SessionType_SESSION_TYPE_FOCUS, 3},
+		{"long break restarts cycle", pb.SessionType_SESSION_TYPE_LONG_BREAK, 4, 4, pb.SessionType_SESSION_TYPE_FOCUS, 1},
+		{"interval of one", pb.SessionType_SESSION_TYPE_FOCUS, 1, 1, pb.SessionType_SESSION_TYPE_LONG_BREAK, 1},
+		{"missing interval uses default", pb.SessionType_SESSION_TYPE_FOCUS, 3, 0, pb.SessionType_SESSION_TYPE_SHORT_BREAK, 3},
+	}
+	for _, c := range cases {
+		gotType, gotNumber := nextInCycle(c.lastType, c.lastNumber, c.interval)
+		if gotType != c.wantType || gotNumber != c.wantNumber {
+			t.Errorf("%s: expected %v #%d, got %v #%d", c.name, c.wantType, c.wantNumber, gotType, gotNumber)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+File: internal/pomodoro/hub.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: In-process pub/sub hub fanning session events out to WatchSessions subscribers.
+*/
+
+package pomodoro
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before it is dropped.
+const subscriberBuffer = 32
+
+// Hub fans session events out to the subscribers of each user.
+// It only reaches subscribers connected to this process.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan *pb.SessionEvent]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[chan *pb.SessionEvent]struct{})}
+}
+
+// Subscribe registers a subscriber for userID's events. The returned channel is
+// closed when cancel is called or when the subscriber falls too far behind.
+func (h *Hub) Subscribe(userID string) (<-chan *pb.SessionEvent, func()) {
+	ch := make(chan *pb.SessionEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan *pb.SessionEvent]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, ch)
+	}
+}
+
+// Publish delivers event to every subscriber of userID without blocking.
+// Subscribers whose buffer is full are dropped; they must reconnect and refetch.
+func (h *Hub) Publish(userID string, event *pb.SessionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[userID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Dropping slow session subscriber for user %s", userID)
+			h.remove(userID, ch)
+		}
+	}
+}
+
+// remove unregisters and closes ch. The caller must hold h.mu.
+func (h *Hub) remove(userID string, ch chan *pb.SessionEvent) {
+	subs := h.subs[userID]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subs, userID)
+	}
+}
+
+// publish emits an event for session on the service hub.
+func (s *Service) publish(eventType pb.SessionEventType, session *pb.PomodoroSession) {
+	s.hub.Publish(session.UserId, &pb.SessionEvent{
+		Type:       eventType,
+		Session:    session,
+		OccurredAt: timestamppb.New(time.Now()),
+	})
+}
+
+// transitionEvent maps a status change to the event reported to subscribers.
+// Writes that keep the status are progress checkpoints.
+func transitionEvent(from, to pb.SessionStatus) pb.SessionEventType {
+	if from == to {
+		return pb.SessionEventType_SESSION_EVENT_TYPE_PROGRESS
+	}
+
+	switch to {
+	case pb.SessionStatus_SESSION_STATUS_IN_PROGRESS:
+		if from == pb.SessionStatus_SESSION_STATUS_PENDING {
+			return pb.SessionEventType_SESSION_EVENT_TYPE_RESUMED
+		}
+		return pb.SessionEventType_SESSION_EVENT_TYPE_STARTED
+	case pb.SessionStatus_SESSION_STATUS_PENDING:
+		return pb.SessionEventType_SESSION_EVENT_TYPE_PAUSED
+	case pb.SessionStatus_SESSION_STATUS_COMPLETED:
+		return pb.SessionEventType_SESSION_EVENT_TYPE_COMPLETED
+	case pb.SessionStatus_SESSION_STATUS_ABANDONED:
+		return pb.SessionEventType_SESSION_EVENT_TYPE_ABANDONED
+	default:
+		return pb.SessionEventType_SESSION_EVENT_TYPE_PROGRESS
+	}
+}
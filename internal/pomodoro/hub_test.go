@@ -0,0 +1,64 @@
+/*
+File: internal/pomodoro/hub_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the session event hub.
+*/
+
+package pomodoro
+
+import (
+	"testing"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+)
+
+func TestHubPublishesToUserSubscribers(t *testing.T) {
+	hub := NewHub()
+
+	desktop, cancelDesktop := hub.Subscribe("user-a")
+	defer cancelDesktop()
+	mobile, cancelMobile := hub.Subscribe("user-a")
+	defer cancelMobile()
+	other, cancelOther := hub.Subscribe("user-b")
+	defer cancelOther()
+
+	event := &pb.SessionEvent{Type: pb.SessionEventType_SESSION_EVENT_TYPE_STARTED}
+	hub.Publish("user-a", event)
+
+	for name, ch := range map[string]<-chan *pb.SessionEvent{"desktop": desktop, "mobile": mobile} {
+		select {
+		case got := <-ch:
+			if got != event {
+				t.Errorf("%s: received unexpected event %v", name, got)
+			}
+		default:
+			t.Errorf("%s: expected an event", name)
+		}
+	}
+
+	select {
+	case got := <-other:
+		t.Errorf("Expected no event for another user, got %v", got)
+	default:
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+
+	events, cancel := hub.Subscribe("user-a")
+	defer cancel()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.Publish("user-a", &pb.SessionEvent{Type: pb.SessionEventType_SESSION_EVENT_TYPE_PROGRESS})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("Expected %d buffered events before the channel closed, got %d", subscriberBuffer, received)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+File: internal/pomodoro/import.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Batch import of finished sessions recorded offline.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxImportBatch caps the number of sessions in one ImportSessions call.
+	maxImportBatch = 500
+	// importClockSkew tolerates client clocks running slightly ahead of the server.
+	importClockSkew = time.Minute
+)
+
+// importedSession is a validated ImportedSession with defaults applied.
+type importedSession struct {
+	sessionID     string
+	taskID        string
+	sessionType   pb.SessionType
+	numberInCycle int32
+	status        pb.SessionStatus
+	start         time.Time
+	end           time.Time
+	progress      time.Duration
+	duration      time.Duration
+}
+
+// normalizeImported validates one imported session and fills in its defaults.
+// It returns a non-empty reason if the session must be rejected.
+func normalizeImported(in *pb.ImportedSession, settings userSettings, now time.Time) (importedSession, string) {
+	out := importedSession{
+		sessionID:     in.SessionId,
+		taskID:        in.TaskId,
+		sessionType:   in.SessionType,
+		numberInCycle: in.NumberInCycle,
+		status:        in.Status,
+	}
+
+	if _, err := uuid.Parse(in.SessionId); err != nil {
+		return out, "session_id must be a valid UUID"
+	}
+	if _, err := uuid.Parse(in.TaskId); err != nil {
+		return out, "task_id must be a valid UUID"
+	}
+	if in.StartTime == nil || in.EndTime == nil {
+		return out, "start_time and end_time are required"
+	}
+	out.start = in.StartTime.AsTime()
+	out.end = in.EndTime.AsTime()
+	if !out.end.After(out.start) {
+		return out, "end_time must be after start_time"
+	}
+	if out.end.After(now.Add(importClockSkew)) {
+		return out, "end_time is in the future"
+	}
+
+	switch out.status {
+	case pb.SessionStatus_SESSION_STATUS_UNSPECIFIED:
+		out.status = pb.SessionStatus_SESSION_STATUS_COMPLETED
+	case pb.SessionStatus_SESSION_STATUS_COMPLETED, pb.SessionStatus_SESSION_STATUS_ABANDONED:
+	default:
+		return out, "only completed or abandoned sessions can be imported"
+	}
+	if out.sessionType == pb.SessionType_SESSION_TYPE_UNSPECIFIED {
+		out.sessionType = pb.SessionType_SESSION_TYPE_FOCUS
+	}
+	if out.numberInCycle <= 0 {
+		out.numberInCycle = 1
+	}
+
+	if in.Progress < 0 || in.Duration < 0 {
+		return out, "progress and duration must not be negative"
+	}
+	window := out.end.Sub(out.start)
+	out.progress = time.Duration(in.Progress) * time.Second
+	if in.Progress == 0 {
+		out.progress = window.Truncate(time.Second)
+	}
+	if out.progress > window {
+		return out, "progress exceeds the time between start_time and end_time"
+	}
+	out.duration = time.Duration(in.Duration) * time.Second
+	if in.Duration == 0 {
+		out.duration = settings.durationFor(out.sessionType)
+	}
+	if out.progress > out.duration {
+		out.duration = out.progress
+	}
+
+	return out, ""
+}
+
+// ImportSessions stores finished sessions recorded offline. Each item is accepted,
+// reported as a duplicate of an earlier import, or rejected with a reason; rejected
+// items do not prevent the others from being stored.
+func (s *Service) ImportSessions(ctx context.Context, req *pb.ImportSessionsRequest) (*pb.ImportSessionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required (path parameter)")
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+	if len(req.Sessions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "sessions is required")
+	}
+	if len(req.Sessions) > maxImportBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d sessions can be imported at once", maxImportBatch)
+	}
+
+	settings, err := s.loadSettings(ctx, req.UserId)
+	if err != nil {
+		log.Printf("Failed to load settings for user %s: %v", req.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to load user settings")
+	}
+
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin session import: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import sessions")
+	}
+	defer tx.Rollback()
+
+	// Serialize imports per user so concurrent uploads cannot both pass the overlap check.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, req.UserId); err != nil {
+		log.Printf("Failed to lock sessions of user %s for import: %v", req.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to import sessions")
+	}
+
+	now := time.Now()
+	resp := &pb.ImportSessionsResponse{Results: make([]*pb.ImportResult, 0, len(req.Sessions))}
+	seen := make(map[string]bool, len(req.Sessions))
+	var acceptedIDs []string
+
+	for _, item := range req.Sessions {
+		result := &pb.ImportResult{SessionId: item.SessionId}
+		resp.Results = append(resp.Results, result)
+
+		imported, reason := normalizeImported(item, settings, now)
+		if reason != "" {
+			result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_REJECTED
+			result.Reason = reason
+			continue
+		}
+		if seen[imported.sessionID] {
+			result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_DUPLICATE
+			continue
+		}
+		seen[imported.sessionID] = true
+
+		var owner string
+		err := tx.QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE session_id = $1`, imported.sessionID).Scan(&owner)
+		switch {
+		case err == nil && owner == req.UserId:
+			result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_DUPLICATE
+			continue
+		case err == nil:
+			result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_REJECTED
+			result.Reason = "session_id is already in use"
+			continue
+		case err != sql.ErrNoRows:
+			log.Printf("Failed to look up imported session %s: %v", imported.sessionID, err)
+			return nil, status.Error(codes.Internal, "failed to import sessions")
+		}
+
+		// Sessions accepted earlier in this batch are already inserted, so they count too.
+		var overlapping string
+		err = tx.QueryRowContext(ctx, `
+            SELECT session_id FROM sessions
+            WHERE user_id = $1 AND status <> $2 AND start_time < $4 AND end_time > $3
+            LIMIT 1`,
+			req.UserId,
+			helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_IDLE),
+			imported.start,
+			imported.end,
+		).Scan(&overlapping)
+		switch {
+		case err == nil:
+			result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_REJECTED
+			result.Reason = "overlaps session " + overlapping
+			continue
+		case err != sql.ErrNoRows:
+			log.Printf("Failed to check overlap for imported session %s: %v", imported.sessionID, err)
+			return nil, status.Error(codes.Internal, "failed to import sessions")
+		}
+
+		if err := insertImportedSession(ctx, tx, req.UserId, imported, now); err != nil {
+			log.Printf("Failed to insert imported session %s: %v", imported.sessionID, err)
+			return nil, status.Error(codes.Internal, "failed to import sessions")
+		}
+		result.Status = pb.ImportResultStatus_IMPORT_RESULT_STATUS_ACCEPTED
+		acceptedIDs = append(acceptedIDs, imported.sessionID)
+	}
+
+	var accepted []*pb.PomodoroSession
+	for _, id := range acceptedIDs {
+		session, _, err := getSession(ctx, tx, id, false, now)
+		if err != nil {
+			log.Printf("Failed to retrieve imported session %s: %v", id, err)
+			return nil, status.Error(codes.Internal, "failed to import sessions")
+		}
+		accepted = append(accepted, session)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit session import: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import sessions")
+	}
+
+	resp.Accepted = int32(len(accepted))
+	log.Printf("Imported %d of %d sessions for user %s", resp.Accepted, len(req.Sessions), req.UserId)
+	for _, session := range accepted {
+		s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_CREATED, session)
+		s.creditTask(ctx, session)
+	}
+	return resp, nil
+}
+
+// insertImportedSession writes a finished session as recorded by the client.
+func insertImportedSession(ctx context.Context, q querier, userID string, in importedSession, now time.Time) error {
+	_, err := q.ExecContext(ctx, `
+        INSERT INTO sessions (
+            session_id, user_id, task_id, start_time, progress, end_time, status,
+            session_type, number_in_cycle, last_update, duration, paused_duration
+        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+		in.sessionID,
+		userID,
+		in.taskID,
+		in.start,
+		int32(in.progress/time.Second),
+		in.end,
+		helper.SessionStatusDbEnumToString(in.status),
+		helper.SessionTypeDbEnumToString(in.sessionType),
+		in.numberInCycle,
+		now,
+		int32(in.duration/time.Second),
+		int32((in.end.Sub(in.start)-in.progress)/time.Second),
+	)
+	return err
+}
@@ -0,0 +1,57 @@
+/*
+File: internal/pomodoro/import_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for validating imported sessions.
+*/
+
+package pomodoro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNormalizeImported(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-2 * time.Hour)
+	valid := func() *pb.ImportedSession {
+		return &pb.ImportedSession{
+			SessionId: uuid.NewString(),
+			TaskId:    uuid.NewString(),
+			StartTime: timestamppb.New(start),
+			EndTime:   timestamppb.New(start.Add(25 * time.Minute)),
+		}
+	}
+
+	got, reason := normalizeImported(valid(), defaultSettings, now)
+	if reason != "" {
+		t.Fatalf("Expected valid session, got %q", reason)
+	}
+	if got.status != pb.SessionStatus_SESSION_STATUS_COMPLETED || got.sessionType != pb.SessionType_SESSION_TYPE_FOCUS {
+		t.Errorf("Expected completed focus defaults, got %v %v", got.status, got.sessionType)
+	}
+	if got.progress != 25*time.Minute || got.duration != defaultSettings.pomodoroDuration {
+		t.Errorf("Expected 25m progress and default duration, got %v and %v", got.progress, got.duration)
+	}
+
+	cases := map[string]func(*pb.ImportedSession){
+		"bad id":         func(in *pb.ImportedSession) { in.SessionId = "offline-1" },
+		"missing end":    func(in *pb.ImportedSession) { in.EndTime = nil },
+		"reversed range": func(in *pb.ImportedSession) { in.EndTime = timestamppb.New(start.Add(-time.Minute)) },
+		"future":         func(in *pb.ImportedSession) { in.EndTime = timestamppb.New(now.Add(time.Hour)) },
+		"running":        func(in *pb.ImportedSession) { in.Status = pb.SessionStatus_SESSION_STATUS_IN_PROGRESS },
+		"long progress":  func(in *pb.ImportedSession) { in.Progress = 3600 },
+	}
+	for name, mutate := range cases {
+		in := valid()
+		mutate(in)
+		if _, reason := normalizeImported(in, defaultSettings, now); reason == "" {
+			t.Errorf("%s: expected rejection", name)
+		}
+	}
+}
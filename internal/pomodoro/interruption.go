@@ -0,0 +1,204 @@
+/*
+File: internal/pomodoro/interruption.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Interruption logging for pomodoro sessions.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxInterruptionNote bounds the free-text note of an interruption.
+const maxInterruptionNote = 1000
+
+// CreateInterruption logs an interruption against a session. If paused_timer is set
+// and the session is running, the session is paused in the same transaction.
+func (s *Service) CreateInterruption(ctx context.Context, req *pb.CreateInterruptionRequest) (*pb.CreateInterruptionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if req.Kind == pb.InterruptionKind_INTERRUPTION_KIND_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "kind is required")
+	}
+	if len(req.Note) > maxInterruptionNote {
+		return nil, status.Errorf(codes.InvalidArgument, "note must be at most %d characters", maxInterruptionNote)
+	}
+
+	now := time.Now()
+	occurredAt := now
+	if req.OccurredAt != nil && !req.OccurredAt.AsTime().IsZero() {
+		occurredAt = req.OccurredAt.AsTime()
+	}
+
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin interruption insert: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create interruption")
+	}
+	defer tx.Rollback()
+
+	session, t, err := getSession(ctx, tx, req.SessionId, true, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		log.Printf("Failed to retrieve session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve session")
+	}
+
+	// Only record paused_timer when the interruption actually paused the clock.
+	paused := false
+	if req.PausedTimer && t.status == pb.SessionStatus_SESSION_STATUS_IN_PROGRESS {
+		if err := t.transition(pb.SessionStatus_SESSION_STATUS_PENDING, now); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot pause session: %v", err)
+		}
+		if err := saveTimer(ctx, tx, req.SessionId, t, now); err != nil {
+			log.Printf("Failed to pause session %s: %v", req.SessionId, err)
+			return nil, status.Error(codes.Internal, "failed to create interruption")
+		}
+		paused = true
+	}
+
+	interruption := &pb.Interruption{
+		InterruptionId: uuid.NewString(),
+		SessionId:      req.SessionId,
+		Kind:           req.Kind,
+		Note:           req.Note,
+		PausedTimer:    paused,
+		OccurredAt:     timestamppb.New(occurredAt),
+	}
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO interruptions (interruption_id, session_id, kind, note, paused_timer, occurred_at)
+        VALUES ($1,$2,$3,$4,$5,$6)`,
+		interruption.InterruptionId,
+		interruption.SessionId,
+		helper.InterruptionKindDbEnumToString(interruption.Kind),
+		interruption.Note,
+		interruption.PausedTimer,
+		occurredAt,
+	)
+	if err != nil {
+		log.Printf("Failed to create interruption for session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to create interruption")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit interruption for session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to create interruption")
+	}
+
+	t.fill(session, now)
+	if req.Kind == pb.InterruptionKind_INTERRUPTION_KIND_EXTERNAL {
+		session.ExternalInterruptions++
+	} else {
+		session.InternalInterruptions++
+	}
+
+	log.Printf("Interruption %s logged for session %s", interruption.InterruptionId, req.SessionId)
+	if paused {
+		session.LastUpdate = timestamppb.New(now)
+		s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_PAUSED, session)
+	} else {
+		s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_PROGRESS, session)
+	}
+	return &pb.CreateInterruptionResponse{Interruption: interruption, Session: session}, nil
+}
+
+// GetInterruptions lists the interruptions of a session, oldest first.
+func (s *Service) GetInterruptions(ctx context.Context, req *pb.GetInterruptionsRequest) (*pb.GetInterruptionsResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	var exists bool
+	err := s.db.PomodoroDB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM sessions WHERE session_id = $1)", req.SessionId).Scan(&exists)
+	if err != nil {
+		log.Printf("Failed to check session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve interruptions")
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	rows, err := s.db.PomodoroDB.QueryContext(ctx, `
+        SELECT interruption_id, session_id, kind, note, paused_timer, occurred_at
+        FROM interruptions
+        WHERE session_id = $1
+        ORDER BY occurred_at, interruption_id`, req.SessionId)
+	if err != nil {
+		log.Printf("Failed to query interruptions: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve interruptions")
+	}
+	defer rows.Close()
+
+	var interruptions []*pb.Interruption
+	for rows.Next() {
+		var (
+			interruption pb.Interruption
+			kindStr      string
+			occurredAt   time.Time
+		)
+		if err := rows.Scan(
+			&interruption.InterruptionId,
+			&interruption.SessionId,
+			&kindStr,
+			&interruption.Note,
+			&interruption.PausedTimer,
+			&occurredAt,
+		); err != nil {
+			log.Printf("Failed to scan interruption: %v", err)
+			return nil, status.Error(codes.Internal, "failed to retrieve interruptions")
+		}
+		interruption.Kind = helper.InterruptionKindDbStringToEnum(kindStr)
+		interruption.OccurredAt = timestamppb.New(occurredAt)
+		interruptions = append(interruptions, &interruption)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating over interruptions: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve interruptions")
+	}
+
+	return &pb.GetInterruptionsResponse{Interruptions: interruptions}, nil
+}
+
+// DeleteInterruption removes an interruption logged by mistake. It does not resume the
+// session if the interruption had paused it.
+func (s *Service) DeleteInterruption(ctx context.Context, req *pb.DeleteInterruptionRequest) (*pb.DeleteInterruptionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if req.InterruptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "interruption_id is required")
+	}
+
+	res, err := s.db.PomodoroDB.ExecContext(ctx,
+		"DELETE FROM interruptions WHERE interruption_id = $1 AND session_id = $2",
+		req.InterruptionId, req.SessionId)
+	if err != nil {
+		log.Printf("Failed to delete interruption: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete interruption")
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		if session, _, err := getSession(ctx, s.db.PomodoroDB, req.SessionId, false, time.Now()); err == nil {
+			s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_PROGRESS, session)
+		}
+	}
+
+	return &pb.DeleteInterruptionResponse{Success: true}, nil
+}
@@ -0,0 +1,148 @@
+/*
+File: internal/pomodoro/reaper.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Background worker that closes sessions left running or paused by a client that went away.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// reapBatch caps how many stale sessions one reaper pass handles.
+const reapBatch = 100
+
+// reapOutcome decides how a stale session is closed and at what time.
+// A running session whose timer has run out is completed at the moment it ran out;
+// anything else (paused, or running but short of its duration) is abandoned now.
+func reapOutcome(t *timer, now time.Time) (pb.SessionStatus, time.Time) {
+	if t.status == pb.SessionStatus_SESSION_STATUS_IN_PROGRESS && t.remaining(now) <= 0 {
+		at := t.end
+		if at.IsZero() || at.After(now) {
+			at = now
+		}
+		return pb.SessionStatus_SESSION_STATUS_COMPLETED, at
+	}
+	return pb.SessionStatus_SESSION_STATUS_ABANDONED, now
+}
+
+// isStale reports whether a running or paused session was last updated before cutoff,
+// which is now minus the threshold.
+func isStale(t *timer, lastUpdate, cutoff time.Time) bool {
+	switch t.status {
+	case pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, pb.SessionStatus_SESSION_STATUS_PENDING:
+		return lastUpdate.Before(cutoff)
+	}
+	return false
+}
+
+// RunStaleSessionReaper closes running or paused sessions last updated more than
+// threshold ago, checking every interval until ctx is cancelled.
+func (s *Service) RunStaleSessionReaper(ctx context.Context, threshold, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reapStaleSessions(ctx, threshold)
+		}
+	}
+}
+
+// reapStaleSessions runs one reaper pass.
+func (s *Service) reapStaleSessions(ctx context.Context, threshold time.Duration) {
+	cutoff := time.Now().Add(-threshold)
+	rows, err := s.db.PomodoroDB.QueryContext(ctx, `
+		SELECT session_id
+		FROM sessions
+		WHERE status IN ($1, $2) AND last_update < $3
+		ORDER BY last_update
+		LIMIT $4`,
+		helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS),
+		helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_PENDING),
+		cutoff,
+		reapBatch,
+	)
+	if err != nil {
+		log.Printf("Failed to query stale sessions: %v", err)
+		return
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Failed to scan stale session: %v", err)
+			rows.Close()
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := s.reapSession(ctx, id, cutoff); err != nil {
+			log.Printf("Failed to reap session %s: %v", id, err)
+		}
+	}
+}
+
+// reapSession closes one stale session under a row lock. The session is skipped if a
+// client resumed or closed it after the candidate query ran.
+func (s *Service) reapSession(ctx context.Context, sessionID string, cutoff time.Time) error {
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	session, t, err := getSession(ctx, tx, sessionID, true, now)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isStale(t, session.LastUpdate.AsTime(), cutoff) {
+		return nil
+	}
+
+	prev := t.status
+	to, at := reapOutcome(t, now)
+	if err := t.transition(to, at); err != nil {
+		return err
+	}
+	if err := saveTimer(ctx, tx, sessionID, t, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	t.fill(session, now)
+	session.LastUpdate = timestamppb.New(now)
+
+	log.Printf("Reaped stale session %s: %s -> %s", sessionID, prev, to)
+	s.hub.Publish(session.UserId, &pb.SessionEvent{
+		Type:       transitionEvent(prev, to),
+		Session:    session,
+		OccurredAt: timestamppb.New(now),
+		Reaped:     true,
+	})
+	s.creditTask(ctx, session)
+	return nil
+}
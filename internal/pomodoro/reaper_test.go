@@ -0,0 +1,96 @@
+/*
+File: internal/pomodoro/reaper_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for how the stale session reaper closes sessions.
+*/
+
+package pomodoro
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+)
+
+func TestReapOutcome(t *testing.T) {
+	t0 := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	now := t0.Add(3 * time.Hour)
+
+	// Ran out while the laptop slept: completed when the timer ran out.
+	ranOut := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 25 * time.Minute}
+	if err := ranOut.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	to, at := reapOutcome(ranOut, now)
+	if to != pb.SessionStatus_SESSION_STATUS_COMPLETED || !at.Equal(t0.Add(25*time.Minute)) {
+		t.Errorf("Expected COMPLETED at %v, got %v at %v", t0.Add(25*time.Minute), to, at)
+	}
+	if err := ranOut.transition(to, at); err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+	if ranOut.progress != 25*time.Minute {
+		t.Errorf("Expected full 25m progress, got %v", ranOut.progress)
+	}
+
+	// Left paused: abandoned now.
+	paused := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 25 * time.Minute}
+	if err := paused.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if err := paused.transition(pb.SessionStatus_SESSION_STATUS_PENDING, t0.Add(5*time.Minute)); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	to, at = reapOutcome(paused, now)
+	if to != pb.SessionStatus_SESSION_STATUS_ABANDONED || !at.Equal(now) {
+		t.Errorf("Expected ABANDONED at %v, got %v at %v", now, to, at)
+	}
+
+	// Still running with time left: abandoned now.
+	long := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 5 * time.Hour}
+	if err := long.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if to, _ := reapOutcome(long, now); to != pb.SessionStatus_SESSION_STATUS_ABANDONED {
+		t.Errorf("Expected ABANDONED for a session with time left, got %v", to)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	t0 := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	threshold := 2 * time.Hour
+	cutoffAt := func(now time.Time) time.Time { return now.Add(-threshold) }
+
+	// Running, even with time left: stale threshold after the last update.
+	long := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 4 * time.Hour}
+	if err := long.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if isStale(long, t0, cutoffAt(t0.Add(threshold))) {
+		t.Error("Expected a session updated at the cutoff not to be stale")
+	}
+	if !isStale(long, t0, cutoffAt(t0.Add(threshold+time.Second))) {
+		t.Error("Expected a running session updated before the cutoff to be stale")
+	}
+
+	// Paused: the pause is the last update.
+	paused := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 25 * time.Minute}
+	if err := paused.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if err := paused.transition(pb.SessionStatus_SESSION_STATUS_PENDING, t0.Add(5*time.Minute)); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	pausedAt := t0.Add(5 * time.Minute)
+	if isStale(paused, pausedAt, cutoffAt(pausedAt.Add(threshold))) {
+		t.Error("Expected a session paused at the cutoff not to be stale")
+	}
+	if !isStale(paused, pausedAt, cutoffAt(pausedAt.Add(threshold+time.Minute))) {
+		t.Error("Expected a session paused before the cutoff to be stale")
+	}
+
+	if isStale(&timer{status: pb.SessionStatus_SESSION_STATUS_IDLE}, t0, t0.Add(time.Hour)) {
+		t.Error("Expected idle sessions never to be stale")
+	}
+}
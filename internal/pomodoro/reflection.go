@@ -0,0 +1,202 @@
+/*
+File: internal/pomodoro/reflection.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Post-session reflections (note, focus rating, mood) for focus sessions.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxReflectionNote = 2000
+	maxMoodLength     = 32
+)
+
+// reflectionColumns lists the columns read by scanReflection, for queries
+// selecting FROM reflections r JOIN sessions s.
+const reflectionColumns = `r.session_id, s.user_id, s.task_id, r.note, r.rating, r.mood,
+		s.start_time, r.created_at, r.updated_at`
+
+func scanReflection(row rowScanner) (*pb.Reflection, error) {
+	var (
+		reflection pb.Reflection
+		startTime  time.Time
+		createdAt  time.Time
+		updatedAt  time.Time
+	)
+	if err := row.Scan(
+		&reflection.SessionId,
+		&reflection.UserId,
+		&reflection.TaskId,
+		&reflection.Note,
+		&reflection.Rating,
+		&reflection.Mood,
+		&startTime,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	reflection.SessionStartTime = timestamppb.New(startTime)
+	reflection.CreatedAt = timestamppb.New(createdAt)
+	reflection.UpdatedAt = timestamppb.New(updatedAt)
+	return &reflection, nil
+}
+
+// SaveReflection creates or replaces the reflection of a focus session.
+func (s *Service) SaveReflection(ctx context.Context, req *pb.SaveReflectionRequest) (*pb.SaveReflectionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, status.Error(codes.InvalidArgument, "rating must be between 1 and 5")
+	}
+	if len(req.Note) > maxReflectionNote {
+		return nil, status.Errorf(codes.InvalidArgument, "note must be at most %d characters", maxReflectionNote)
+	}
+	mood := strings.ToLower(strings.TrimSpace(req.Mood))
+	if len(mood) > maxMoodLength {
+		return nil, status.Errorf(codes.InvalidArgument, "mood must be at most %d characters", maxMoodLength)
+	}
+
+	session, _, err := getSession(ctx, s.db.PomodoroDB, req.SessionId, false, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		log.Printf("Failed to retrieve session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve session")
+	}
+	if session.SessionType != pb.SessionType_SESSION_TYPE_FOCUS {
+		return nil, status.Error(codes.FailedPrecondition, "reflections can only be attached to focus sessions")
+	}
+
+	now := time.Now()
+	_, err = s.db.PomodoroDB.ExecContext(ctx, `
+        INSERT INTO reflections (session_id, note, rating, mood, created_at, updated_at)
+        VALUES ($1,$2,$3,$4,$5,$5)
+        ON CONFLICT (session_id) DO UPDATE SET
+            note = EXCLUDED.note,
+            rating = EXCLUDED.rating,
+            mood = EXCLUDED.mood,
+            updated_at = EXCLUDED.updated_at`,
+		req.SessionId, req.Note, req.Rating, mood, now)
+	if err != nil {
+		log.Printf("Failed to save reflection for session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to save reflection")
+	}
+
+	reflection, err := s.getReflection(ctx, req.SessionId)
+	if err != nil {
+		log.Printf("Failed to retrieve saved reflection for session %s: %v", req.SessionId, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve saved reflection")
+	}
+
+	log.Printf("Reflection saved for session %s", req.SessionId)
+	return &pb.SaveReflectionResponse{Reflection: reflection}, nil
+}
+
+// GetReflection returns the reflection of a session.
+func (s *Service) GetReflection(ctx context.Context, req *pb.GetReflectionRequest) (*pb.GetReflectionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	reflection, err := s.getReflection(ctx, req.SessionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "reflection not found")
+		}
+		log.Printf("Failed to retrieve reflection: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve reflection")
+	}
+
+	return &pb.GetReflectionResponse{Reflection: reflection}, nil
+}
+
+// GetReflections lists a user's reflections ordered by session start time,
+// optionally restricted to one task and a [start_from, start_to) window.
+func (s *Service) GetReflections(ctx context.Context, req *pb.GetReflectionsRequest) (*pb.GetReflectionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+	if req.TaskId != "" {
+		if _, err := uuid.Parse(req.TaskId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "task_id must be a valid UUID")
+		}
+	}
+
+	var startFrom, startTo any
+	if req.StartFrom != nil {
+		startFrom = req.StartFrom.AsTime()
+	}
+	if req.StartTo != nil {
+		startTo = req.StartTo.AsTime()
+	}
+	if req.StartFrom != nil && req.StartTo != nil && !req.StartTo.AsTime().After(req.StartFrom.AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "start_to must be after start_from")
+	}
+
+	var taskID any
+	if req.TaskId != "" {
+		taskID = req.TaskId
+	}
+
+	rows, err := s.db.PomodoroDB.QueryContext(ctx, `
+        SELECT `+reflectionColumns+`
+        FROM reflections r
+        JOIN sessions s ON s.session_id = r.session_id
+        WHERE s.user_id = $1
+          AND ($2::uuid IS NULL OR s.task_id = $2::uuid)
+          AND ($3::timestamptz IS NULL OR s.start_time >= $3::timestamptz)
+          AND ($4::timestamptz IS NULL OR s.start_time < $4::timestamptz)
+        ORDER BY s.start_time, r.session_id`, req.UserId, taskID, startFrom, startTo)
+	if err != nil {
+		log.Printf("Failed to query reflections: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve reflections")
+	}
+	defer rows.Close()
+
+	var reflections []*pb.Reflection
+	for rows.Next() {
+		reflection, err := scanReflection(rows)
+		if err != nil {
+			log.Printf("Failed to scan reflection: %v", err)
+			return nil, status.Error(codes.Internal, "failed to retrieve reflections")
+		}
+		reflections = append(reflections, reflection)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating over reflections: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve reflections")
+	}
+
+	return &pb.GetReflectionsResponse{Reflections: reflections}, nil
+}
+
+func (s *Service) getReflection(ctx context.Context, sessionID string) (*pb.Reflection, error) {
+	return scanReflection(s.db.PomodoroDB.QueryRowContext(ctx, `
+        SELECT `+reflectionColumns+`
+        FROM reflections r
+        JOIN sessions s ON s.session_id = r.session_id
+        WHERE r.session_id = $1`, sessionID))
+}
@@ -0,0 +1,159 @@
+/*
+File: internal/pomodoro/repository.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Shared SQL helpers for reading and writing pomodoro sessions.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sessionColumns lists the sessions columns read by scanSession, in scan order.
+// Interruption counts are computed per row, so queries must select FROM sessions unaliased.
+const sessionColumns = `session_id, user_id, task_id, start_time, progress, end_time, status,
+		session_type, number_in_cycle, last_update, duration, paused_at, paused_duration,
+		(SELECT COUNT(*) FROM interruptions i WHERE i.session_id = sessions.session_id AND i.kind = 'internal'),
+		(SELECT COUNT(*) FROM interruptions i WHERE i.session_id = sessions.session_id AND i.kind = 'external')`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanSession reads one row selected with sessionColumns.
+// The returned session has its timer-derived fields filled as of now.
+func scanSession(row rowScanner, now time.Time) (*pb.PomodoroSession, *timer, error) {
+	var (
+		session        pb.PomodoroSession
+		t              timer
+		statusStr      string
+		sessionTypeStr string
+		progress       int32
+		duration       int32
+		pausedDuration int32
+		lastUpdate     time.Time
+	)
+	if err := row.Scan(
+		&session.SessionId,
+		&session.UserId,
+		&session.TaskId,
+		&t.start,
+		&progress,
+		&t.end,
+		&statusStr,
+		&sessionTypeStr,
+		&session.NumberInCycle,
+		&lastUpdate,
+		&duration,
+		&t.pausedAt,
+		&pausedDuration,
+		&session.InternalInterruptions,
+		&session.ExternalInterruptions,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	t.status = helper.SessionStatusDbStringToEnum(statusStr)
+	t.progress = time.Duration(progress) * time.Second
+	t.duration = time.Duration(duration) * time.Second
+	t.pausedDuration = time.Duration(pausedDuration) * time.Second
+
+	session.SessionType = helper.SessionTypeDbStringToEnum(sessionTypeStr)
+	session.LastUpdate = timestamppb.New(lastUpdate)
+	t.fill(&session, now)
+
+	return &session, &t, nil
+}
+
+// getSession loads a session by ID. Pass forUpdate inside a transaction to lock the row.
+func getSession(ctx context.Context, q querier, sessionID string, forUpdate bool, now time.Time) (*pb.PomodoroSession, *timer, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE session_id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	return scanSession(q.QueryRowContext(ctx, query, sessionID), now)
+}
+
+// newSession describes a sessions row to insert.
+type newSession struct {
+	sessionID         string
+	userID            string
+	taskID            string
+	sessionType       pb.SessionType
+	numberInCycle     int32
+	status            pb.SessionStatus
+	start             time.Time
+	duration          time.Duration
+	previousSessionID string
+}
+
+// insertSession writes a new session whose clock starts at ns.start.
+func insertSession(ctx context.Context, q querier, ns newSession) error {
+	var previousSessionID any
+	if ns.previousSessionID != "" {
+		previousSessionID = ns.previousSessionID
+	}
+	_, err := q.ExecContext(ctx, `
+        INSERT INTO sessions (
+            session_id, user_id, task_id, start_time, progress, end_time, status,
+            session_type, number_in_cycle, last_update, duration, previous_session_id
+        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+		ns.sessionID,
+		ns.userID,
+		ns.taskID,
+		ns.start,
+		int32(0), // initial progress
+		ns.start.Add(ns.duration),
+		helper.SessionStatusDbEnumToString(ns.status),
+		helper.SessionTypeDbEnumToString(ns.sessionType),
+		ns.numberInCycle,
+		ns.start,
+		int32(ns.duration/time.Second),
+		previousSessionID,
+	)
+	return err
+}
+
+// saveTimer persists the clock state of a session.
+func saveTimer(ctx context.Context, q querier, sessionID string, t *timer, now time.Time) error {
+	var pausedAt any
+	if t.pausedAt.Valid {
+		pausedAt = t.pausedAt.Time
+	}
+	_, err := q.ExecContext(ctx, `UPDATE sessions SET
+		start_time = $1,
+		end_time = $2,
+		progress = $3,
+		status = $4,
+		paused_at = $5,
+		paused_duration = $6,
+		last_update = $7
+		WHERE session_id = $8`,
+		t.start,
+		t.end,
+		int32(t.progress/time.Second),
+		helper.SessionStatusDbEnumToString(t.status),
+		pausedAt,
+		int32(t.pausedDuration/time.Second),
+		now,
+		sessionID,
+	)
+	return err
+}
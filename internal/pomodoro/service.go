@@ -10,7 +10,9 @@ package pomodoro
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"database/sql"
@@ -19,18 +21,31 @@ import (
 	"github.com/latrung124/Totodoro-Backend/internal/database"
 	"github.com/latrung124/Totodoro-Backend/internal/helper"
 	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"github.com/lib/pq"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Page size bounds for GetSessions.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
 type Service struct {
 	pb.UnimplementedPomodoroServiceServer
-	db *database.Connections
+	db       *database.Connections
+	settings SettingsProvider
+	tasks    TaskCreditor
+	hub      *Hub
 }
 
-func NewService(db *database.Connections) *Service {
-	return &Service{db: db}
+// NewService creates a pomodoro service. settings may be nil, in which case
+// the default timer durations are used for every user; tasks may be nil, in
+// which case completed sessions are not credited to their task.
+func NewService(db *database.Connections, settings SettingsProvider, tasks TaskCreditor) *Service {
+	return &Service{db: db, settings: settings, tasks: tasks, hub: NewHub()}
 }
 
 // CreatePomodoro creates a new pomodoro session for a user.
@@ -56,130 +71,143 @@ func (s *Service) CreateSession(ctx context.Context, req *pb.CreateSessionReques
 		startTime = req.StartTime.AsTime()
 	}
 
-	sessionStatus := helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_IDLE)
-	sessionType := helper.SessionTypeDbEnumToString(req.SessionType)
+	settings, err := s.loadSettings(ctx, req.UserId)
+	if err != nil {
+		log.Printf("Failed to load settings for user %s: %v", req.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to load user settings")
+	}
+
+	sessionTypeEnum := req.SessionType
+	if sessionTypeEnum == pb.SessionType_SESSION_TYPE_UNSPECIFIED {
+		sessionTypeEnum = pb.SessionType_SESSION_TYPE_FOCUS
+	}
+	duration := settings.durationFor(sessionTypeEnum)
+
 	numberInCycle := int32(1)
 	if req.NumberInCycle > 0 {
 		numberInCycle = req.NumberInCycle
 	}
 
-	_, err := s.db.PomodoroDB.ExecContext(ctx, `
-        INSERT INTO sessions (
-            session_id, user_id, task_id, start_time, progress, end_time, status,
-            session_type, number_in_cycle, last_update
-        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
-		sessionID,
-		req.UserId,
-		req.TaskId,
-		startTime,
-		int32(0),                      // initial progress
-		startTime.Add(25*time.Minute), // default end time (could be adjusted later)
-		sessionStatus,
-		sessionType,
-		numberInCycle,
-		startTime,
-	)
+	err = insertSession(ctx, s.db.PomodoroDB, newSession{
+		sessionID:     sessionID,
+		userID:        req.UserId,
+		taskID:        req.TaskId,
+		sessionType:   sessionTypeEnum,
+		numberInCycle: numberInCycle,
+		status:        pb.SessionStatus_SESSION_STATUS_IDLE,
+		start:         startTime,
+		duration:      duration,
+	})
 	if err != nil {
 		log.Printf("Failed to create session (user_id=%s task_id=%s): %v", req.UserId, req.TaskId, err)
 		return nil, status.Error(codes.Internal, "failed to create session")
 	}
 
-	var session pb.PomodoroSession
-	row := s.db.PomodoroDB.QueryRowContext(ctx, `
-        SELECT session_id, user_id, task_id, start_time, progress, end_time, status,
-               session_type, number_in_cycle, last_update
-        FROM sessions WHERE session_id = $1`, sessionID)
-
-	var (
-		start      time.Time
-		end        time.Time
-		lastUpdate time.Time
-		statusStr  string
-		typeStr    string
-		progress   int32
-		nCycle     int32
-	)
-	if err := row.Scan(
-		&session.SessionId,
-		&session.UserId,
-		&session.TaskId,
-		&start,
-		&progress,
-		&end,
-		&statusStr,
-		&typeStr,
-		&nCycle,
-		&lastUpdate,
-	); err != nil {
+	session, _, err := getSession(ctx, s.db.PomodoroDB, sessionID, false, time.Now())
+	if err != nil {
 		log.Printf("Failed to retrieve created session %s: %v", sessionID, err)
 		return nil, status.Error(codes.Internal, "failed to retrieve created session")
 	}
 
-	session.StartTime = timestamppb.New(start)
-	session.EndTime = timestamppb.New(end)
-	session.Progress = progress
-	session.Status = helper.SessionStatusDbStringToEnum(statusStr)
-	session.SessionType = helper.SessionTypeDbStringToEnum(typeStr)
-	session.NumberInCycle = nCycle
-	session.LastUpdate = timestamppb.New(lastUpdate)
-
 	log.Printf("Session created successfully: %s (user_id=%s task_id=%s)", session.SessionId, session.UserId, session.TaskId)
-	return &pb.CreateSessionResponse{Session: &session}, nil
+	s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_CREATED, session)
+	return &pb.CreateSessionResponse{Session: session}, nil
 }
 
+// GetSessions lists a user's sessions ordered by start_time, one page at a time.
+// Pages are keyed on (start_time, session_id), so rows inserted while paging neither
+// shift nor repeat later pages.
 func (s *Service) GetSessions(ctx context.Context, req *pb.GetSessionsRequest) (*pb.GetSessionsResponse, error) {
 	if req.UserId == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id is required") // fixed message
 	}
+	if req.TaskId != "" {
+		if _, err := uuid.Parse(req.TaskId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "task_id must be a valid UUID")
+		}
+	}
+	if req.StartFrom != nil && req.StartTo != nil && !req.StartTo.AsTime().After(req.StartFrom.AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "start_to must be after start_from")
+	}
+
+	pageSize := helper.ClampPageSize(req.PageSize, defaultPageSize, maxPageSize)
+	asc := req.Order == pb.SortOrder_SORT_ORDER_ASC
+	ordering := helper.PageOrdering("start_time", !asc)
+
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE user_id = $1`
+	args := []any{req.UserId}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	rows, err := s.db.PomodoroDB.QueryContext(ctx, `
-        SELECT session_id, task_id, start_time, progress, end_time, status,
-		session_type, number_in_cycle, last_update
-        FROM sessions
-        WHERE user_id = $1`, req.UserId)
+	if req.TaskId != "" {
+		query += ` AND task_id = ` + arg(req.TaskId)
+	}
+	if len(req.Statuses) > 0 {
+		statuses := make([]string, len(req.Statuses))
+		for i, st := range req.Statuses {
+			statuses[i] = helper.SessionStatusDbEnumToString(st)
+		}
+		query += ` AND status = ANY(` + arg(pq.Array(statuses)) + `)`
+	}
+	if len(req.SessionTypes) > 0 {
+		types := make([]string, len(req.SessionTypes))
+		for i, st := range req.SessionTypes {
+			types[i] = helper.SessionTypeDbEnumToString(st)
+		}
+		query += ` AND session_type = ANY(` + arg(pq.Array(types)) + `)`
+	}
+	if req.StartFrom != nil {
+		query += ` AND start_time >= ` + arg(req.StartFrom.AsTime())
+	}
+	if req.StartTo != nil {
+		query += ` AND start_time < ` + arg(req.StartTo.AsTime())
+	}
+	if req.PageToken != "" {
+		keys, err := helper.DecodePageToken(req.PageToken, ordering, 2)
+		if err == helper.ErrPageTokenOrdering {
+			return nil, status.Error(codes.InvalidArgument, "page_token was issued for a different sort order")
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		after, err := time.Parse(time.RFC3339Nano, keys[0])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		cmp := "<"
+		if asc {
+			cmp = ">"
+		}
+		query += ` AND (start_time, session_id) ` + cmp + ` (` + arg(after) + `, ` + arg(keys[1]) + `)`
+	}
+	if asc {
+		query += ` ORDER BY start_time ASC, session_id ASC`
+	} else {
+		query += ` ORDER BY start_time DESC, session_id DESC`
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	query += ` LIMIT ` + arg(pageSize+1)
+
+	rows, err := s.db.PomodoroDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("Failed to query sessions: %v", err)
 		return nil, status.Error(codes.Internal, "failed to retrieve sessions")
 	}
 	defer rows.Close()
 
+	now := time.Now()
 	var sessions []*pb.PomodoroSession
+	var starts []time.Time
 	for rows.Next() {
-		var (
-			session        pb.PomodoroSession
-			start          time.Time
-			end            time.Time
-			statusStr      string
-			sessionTypeStr string
-			lastUpdate     time.Time
-			progress       int32
-			numberInCycle  int32
-		)
-		if err := rows.Scan(
-			&session.SessionId,
-			&session.TaskId,
-			&start,
-			&progress,
-			&end,
-			&statusStr,
-			&sessionTypeStr,
-			&numberInCycle,
-			&lastUpdate); err != nil {
+		session, t, err := scanSession(rows, now)
+		if err != nil {
 			log.Printf("Failed to scan session: %v", err)
 			return nil, status.Error(codes.Internal, "failed to retrieve sessions")
 		}
-		session.UserId = req.UserId
-		session.StartTime = timestamppb.New(start)
-		session.EndTime = timestamppb.New(end)
-		session.Progress = progress
-
-		session.Status = helper.SessionStatusDbStringToEnum(statusStr)
-		session.SessionType = helper.SessionTypeDbStringToEnum(sessionTypeStr)
-
-		session.NumberInCycle = numberInCycle
-		session.LastUpdate = timestamppb.New(lastUpdate)
-
-		sessions = append(sessions, &session)
+		sessions = append(sessions, session)
+		starts = append(starts, t.start)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -187,7 +215,13 @@ func (s *Service) GetSessions(ctx context.Context, req *pb.GetSessionsRequest) (
 		return nil, status.Error(codes.Internal, "failed to retrieve sessions")
 	}
 
-	return &pb.GetSessionsResponse{Sessions: sessions}, nil
+	resp := &pb.GetSessionsResponse{Sessions: sessions}
+	if len(sessions) > int(pageSize) {
+		resp.Sessions = sessions[:pageSize]
+		last := pageSize - 1
+		resp.NextPageToken = helper.EncodePageToken(ordering, starts[last].Format(time.RFC3339Nano), sessions[last].SessionId)
+	}
+	return resp, nil
 }
 
 func (s *Service) GetSessionById(ctx context.Context, req *pb.GetSessionByIdRequest) (*pb.GetSessionByIdResponse, error) {
@@ -195,17 +229,8 @@ func (s *Service) GetSessionById(ctx context.Context, req *pb.GetSessionByIdRequ
 		return nil, status.Error(codes.InvalidArgument, "session_id is required")
 	}
 
-	var session pb.PomodoroSession
-	var start, end, lastUpdate time.Time
-	var statusStr, sessionTypeStr string
-	var progress int32
-	var numberInCycle int32
-
-	row := s.db.PomodoroDB.QueryRowContext(ctx, `SELECT session_id, user_id, task_id, start_time, progress, end_time, status,
-		session_type, number_in_cycle, last_update
-		FROM sessions WHERE session_id = $1`, req.SessionId)
-
-	if err := row.Scan(&session.SessionId, &session.UserId, &session.TaskId, &start, &progress, &end, &statusStr, &sessionTypeStr, &numberInCycle, &lastUpdate); err != nil {
+	session, _, err := getSession(ctx, s.db.PomodoroDB, req.SessionId, false, time.Now())
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Error(codes.NotFound, "session not found")
 		}
@@ -213,15 +238,7 @@ func (s *Service) GetSessionById(ctx context.Context, req *pb.GetSessionByIdRequ
 		return nil, status.Error(codes.Internal, "failed to retrieve session")
 	}
 
-	session.StartTime = timestamppb.New(start)
-	session.EndTime = timestamppb.New(end)
-	session.Progress = progress
-	session.Status = helper.SessionStatusDbStringToEnum(statusStr)
-	session.SessionType = helper.SessionTypeDbStringToEnum(sessionTypeStr)
-	session.NumberInCycle = numberInCycle
-	session.LastUpdate = timestamppb.New(lastUpdate)
-
-	return &pb.GetSessionByIdResponse{Session: &session}, nil
+	return &pb.GetSessionByIdResponse{Session: session}, nil
 }
 
 func (s *Service) UpdateSession(ctx context.Context, req *pb.UpdateSessionRequest) (*pb.UpdateSessionResponse, error) {
@@ -233,17 +250,48 @@ func (s *Service) UpdateSession(ctx context.Context, req *pb.UpdateSessionReques
 		return nil, status.Error(codes.InvalidArgument, "status is required")
 	}
 
-	statusStr := helper.SessionStatusDbEnumToString(req.Status)
-	sessionTypeStr := helper.SessionTypeDbEnumToString(req.SessionType)
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin session update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
+	defer tx.Rollback()
 
-	_, err := s.db.PomodoroDB.ExecContext(ctx, `UPDATE sessions SET
-		progress = $1,
-		end_time = $2,
-		status = $3,
-		session_type = $4,
-		number_in_cycle = $5,
-		last_update = $6
-		WHERE session_id = $7`, req.Progress, req.EndTime.AsTime(), statusStr, sessionTypeStr, req.NumberInCycle, req.LastUpdate.AsTime(), req.SessionId)
+	// Status changes go through the same timer transitions as the dedicated
+	// Start/Pause/Resume/Complete/Abandon RPCs; the clock fields are the server's.
+	now := time.Now()
+	_, current, err := getSession(ctx, tx, req.SessionId, true, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		log.Printf("Failed to retrieve session for update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
+	prev := current.status
+	if isTerminal(prev) {
+		return nil, status.Errorf(codes.FailedPrecondition, "session is already %s", prev)
+	}
+	if req.Status != prev {
+		if err := current.transition(req.Status, now); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot change session status from %s to %s", prev, req.Status)
+		}
+		if err := saveTimer(ctx, tx, req.SessionId, current, now); err != nil {
+			log.Printf("Failed to save session timer: %v", err)
+			return nil, status.Error(codes.Internal, "failed to update session")
+		}
+	}
+
+	// An unspecified session type, or a zero number in cycle, leaves the current one.
+	var sessionType any
+	if req.SessionType != pb.SessionType_SESSION_TYPE_UNSPECIFIED {
+		sessionType = helper.SessionTypeDbEnumToString(req.SessionType)
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE sessions SET
+		session_type = COALESCE($1, session_type),
+		number_in_cycle = COALESCE(NULLIF($2, 0), number_in_cycle),
+		last_update = $3
+		WHERE session_id = $4`, sessionType, req.NumberInCycle, now, req.SessionId)
 
 	if err != nil {
 		log.Printf("Failed to update session: %v", err)
@@ -251,31 +299,25 @@ func (s *Service) UpdateSession(ctx context.Context, req *pb.UpdateSessionReques
 	}
 
 	// Retrieve the updated session
-	var session pb.PomodoroSession
-	row := s.db.PomodoroDB.QueryRowContext(ctx, `SELECT session_id, user_id, task_id, start_time, progress, end_time, status,
-		session_type, number_in_cycle, last_update
-		FROM sessions WHERE session_id = $1`, req.SessionId)
-
-	var start, end, lastUpdate time.Time
-	if err := row.Scan(&session.SessionId, &session.UserId, &session.TaskId, &start, &session.Progress, &end, &statusStr, &sessionTypeStr, &session.NumberInCycle, &lastUpdate); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, status.Error(codes.NotFound, "session not found")
-		}
+	session, _, err := getSession(ctx, tx, req.SessionId, false, now)
+	if err != nil {
 		log.Printf("Failed to retrieve updated session: %v", err)
 		return nil, status.Error(codes.Internal, "failed to retrieve updated session")
 	}
 
-	session.StartTime = timestamppb.New(start)
-	session.EndTime = timestamppb.New(end)
-	session.Status = helper.SessionStatusDbStringToEnum(statusStr)
-	session.SessionType = helper.SessionTypeDbStringToEnum(sessionTypeStr)
-
-	session.LastUpdate = timestamppb.New(lastUpdate)
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit session update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
 
 	// Return the updated session
 	log.Printf("Session updated successfully: %s", session.SessionId)
+	s.publish(transitionEvent(prev, session.Status), session)
+	if prev != session.Status {
+		s.creditTask(ctx, session)
+	}
 
-	return &pb.UpdateSessionResponse{Session: &session}, nil
+	return &pb.UpdateSessionResponse{Session: session}, nil
 }
 
 func (s *Service) DeleteSession(ctx context.Context, req *pb.DeleteSessionRequest) (*pb.DeleteSessionResponse, error) {
@@ -283,11 +325,138 @@ func (s *Service) DeleteSession(ctx context.Context, req *pb.DeleteSessionReques
 		return nil, status.Error(codes.InvalidArgument, "session_id is required")
 	}
 
-	_, err := s.db.PomodoroDB.ExecContext(ctx, "DELETE FROM sessions WHERE session_id = $1", req.SessionId)
-	if err != nil {
+	var userID string
+	err := s.db.PomodoroDB.QueryRowContext(ctx, "DELETE FROM sessions WHERE session_id = $1 RETURNING user_id", req.SessionId).Scan(&userID)
+	if err != nil && err != sql.ErrNoRows {
 		log.Printf("Failed to delete session: %v", err)
 		return nil, status.Error(codes.Internal, "failed to delete session")
 	}
+	if err == nil {
+		s.publish(pb.SessionEventType_SESSION_EVENT_TYPE_DELETED, &pb.PomodoroSession{SessionId: req.SessionId, UserId: userID})
+	}
 
 	return &pb.DeleteSessionResponse{Success: true}, nil
 }
+
+// StartSession starts the clock of an idle session.
+func (s *Service) StartSession(ctx context.Context, req *pb.StartSessionRequest) (*pb.StartSessionResponse, error) {
+	session, err := s.transitionSession(ctx, req.SessionId, pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, pb.SessionStatus_SESSION_STATUS_IDLE)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.StartSessionResponse{Session: session}, nil
+}
+
+// PauseSession freezes the clock of a running session.
+func (s *Service) PauseSession(ctx context.Context, req *pb.PauseSessionRequest) (*pb.PauseSessionResponse, error) {
+	session, err := s.transitionSession(ctx, req.SessionId, pb.SessionStatus_SESSION_STATUS_PENDING)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PauseSessionResponse{Session: session}, nil
+}
+
+// ResumeSession restarts the clock of a paused session.
+func (s *Service) ResumeSession(ctx context.Context, req *pb.ResumeSessionRequest) (*pb.ResumeSessionResponse, error) {
+	session, err := s.transitionSession(ctx, req.SessionId, pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, pb.SessionStatus_SESSION_STATUS_PENDING)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ResumeSessionResponse{Session: session}, nil
+}
+
+// CompleteSession finishes a running or paused session.
+func (s *Service) CompleteSession(ctx context.Context, req *pb.CompleteSessionRequest) (*pb.CompleteSessionResponse, error) {
+	session, err := s.transitionSession(ctx, req.SessionId, pb.SessionStatus_SESSION_STATUS_COMPLETED)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CompleteSessionResponse{Session: session}, nil
+}
+
+// AbandonSession gives up on a session that has not completed.
+func (s *Service) AbandonSession(ctx context.Context, req *pb.AbandonSessionRequest) (*pb.AbandonSessionResponse, error) {
+	session, err := s.transitionSession(ctx, req.SessionId, pb.SessionStatus_SESSION_STATUS_ABANDONED)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AbandonSessionResponse{Session: session}, nil
+}
+
+// transitionSession moves a session to status "to" under a row lock.
+// If from is given, the session must currently be in one of those statuses;
+// this keeps Start and Resume from being used interchangeably.
+func (s *Service) transitionSession(ctx context.Context, sessionID string, to pb.SessionStatus, from ...pb.SessionStatus) (*pb.PomodoroSession, error) {
+	if sessionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	tx, err := s.db.PomodoroDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin session transition: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	session, t, err := getSession(ctx, tx, sessionID, true, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		log.Printf("Failed to retrieve session %s: %v", sessionID, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve session")
+	}
+
+	if len(from) > 0 && !slices.Contains(from, t.status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot change session status from %s to %s", t.status, to)
+	}
+	prev := t.status
+	if err := t.transition(to, now); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot change session status from %s to %s", t.status, to)
+	}
+
+	if err := saveTimer(ctx, tx, sessionID, t, now); err != nil {
+		log.Printf("Failed to save session %s: %v", sessionID, err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit session %s: %v", sessionID, err)
+		return nil, status.Error(codes.Internal, "failed to update session")
+	}
+
+	t.fill(session, now)
+	session.LastUpdate = timestamppb.New(now)
+
+	log.Printf("Session %s moved to %s", sessionID, to)
+	s.publish(transitionEvent(prev, to), session)
+	s.creditTask(ctx, session)
+	return session, nil
+}
+
+// WatchSessions streams every state change of the user's sessions until the client disconnects.
+func (s *Service) WatchSessions(req *pb.WatchSessionsRequest, stream pb.PomodoroService_WatchSessionsServer) error {
+	if req.UserId == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+
+	events, cancel := s.hub.Subscribe(req.UserId)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "subscriber fell behind; reconnect and refetch sessions")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"slices"
 	"testing"
 	"time"
 
@@ -19,6 +20,11 @@ import (
 	"github.com/latrung124/Totodoro-Backend/internal/database"
 	"github.com/latrung124/Totodoro-Backend/internal/helper"
 	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	userpb "github.com/latrung124/Totodoro-Backend/internal/proto_package/user_service"
+	"github.com/latrung124/Totodoro-Backend/internal/task_management"
+	"github.com/latrung124/Totodoro-Backend/internal/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -44,6 +50,21 @@ func setupTestDB() (*database.Connections, error) {
 	return connections, nil
 }
 
+func newTestService(connections *database.Connections) *Service {
+	return NewService(connections, user.NewService(connections), task_management.NewService(connections))
+}
+
+type fakeSettings struct {
+	settings *userpb.Settings
+}
+
+func (f fakeSettings) GetSettings(ctx context.Context, req *userpb.GetSettingsRequest) (*userpb.GetSettingsResponse, error) {
+	if f.settings == nil {
+		return nil, status.Error(codes.NotFound, "settings not found")
+	}
+	return &userpb.GetSettingsResponse{Settings: f.settings}, nil
+}
+
 func SeedPomodoroSession(t *testing.T, db *sql.DB, sessionId string, userId string, taskId string, startTime time.Time, endTime time.Time) {
 	progress := 0
 	statusStr := helper.SessionStatusDbEnumToString(pb.SessionStatus_SESSION_STATUS_IDLE)
@@ -89,7 +110,7 @@ func TestCreateSession(t *testing.T) {
 	}
 	defer connections.Close()
 
-	service := NewService(connections)
+	service := newTestService(connections)
 
 	now := time.Now()
 
@@ -151,7 +172,7 @@ func TestGetSessions(t *testing.T) {
 	}
 	defer connections.Close()
 
-	service := NewService(connections)
+	service := newTestService(connections)
 
 	userId := uuid.NewString()
 	taskId := uuid.NewString()
@@ -195,7 +216,7 @@ func TestGetSessionById(t *testing.T) {
 	}
 	defer connections.Close()
 
-	service := NewService(connections)
+	service := newTestService(connections)
 
 	userId := uuid.NewString()
 	taskId := uuid.NewString()
@@ -240,7 +261,7 @@ func TestUpdateSessionResponse(t *testing.T) {
 	}
 	defer connections.Close()
 
-	service := NewService(connections)
+	service := newTestService(connections)
 
 	userId := uuid.NewString()
 	taskId := uuid.NewString()
@@ -251,6 +272,11 @@ func TestUpdateSessionResponse(t *testing.T) {
 	// Seed a test session
 	SeedPomodoroSession(t, connections.PomodoroDB, sessionId, userId, taskId, startTime, endTime)
 
+	// Idle sessions cannot jump straight to completed; start the timer first.
+	if _, err := service.StartSession(context.Background(), &pb.StartSessionRequest{SessionId: sessionId}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
 	// Prepare update values
 	newProgress := int32(120)
 	newStatus := pb.SessionStatus_SESSION_STATUS_COMPLETED
@@ -276,8 +302,9 @@ func TestUpdateSessionResponse(t *testing.T) {
 	if resp.Session.SessionId != sessionId {
 		t.Errorf("Expected SessionId %s, got %s", sessionId, resp.Session.SessionId)
 	}
-	if resp.Session.Progress != newProgress {
-		t.Errorf("Expected Progress %d, got %d", newProgress, resp.Session.Progress)
+	// Clock fields from the client are ignored; the session only just started.
+	if resp.Session.Progress >= newProgress {
+		t.Errorf("Expected the server's progress, got the client's %d", resp.Session.Progress)
 	}
 	if resp.Session.Status != newStatus {
 		t.Errorf("Expected Status %v, got %v", newStatus, resp.Session.Status)
@@ -291,9 +318,10 @@ func TestUpdateSessionResponse(t *testing.T) {
 	if resp.Session.EndTime == nil {
 		t.Fatalf("EndTime is nil in response")
 	}
+	completedAt := time.Now()
 	et := resp.Session.EndTime.AsTime().UTC()
-	if et.Before(endTime.UTC().Add(-time.Second)) || et.After(endTime.UTC().Add(time.Second)) {
-		t.Errorf("Expected EndTime ~%v, got %v", endTime, resp.Session.EndTime.AsTime())
+	if et.Before(completedAt.UTC().Add(-5*time.Second)) || et.After(completedAt.UTC().Add(time.Second)) {
+		t.Errorf("Expected EndTime ~%v, got %v", completedAt, resp.Session.EndTime.AsTime())
 	}
 	if resp.Session.LastUpdate == nil {
 		t.Fatalf("LastUpdate is nil in response")
@@ -322,8 +350,8 @@ func TestUpdateSessionResponse(t *testing.T) {
 	expStatusStr := helper.SessionStatusDbEnumToString(newStatus)
 	expTypeStr := helper.SessionTypeDbEnumToString(newType)
 
-	if gotProgress != newProgress {
-		t.Errorf("DB progress mismatch: expected %d, got %d", newProgress, gotProgress)
+	if gotProgress != resp.Session.Progress {
+		t.Errorf("DB progress mismatch: expected %d, got %d", resp.Session.Progress, gotProgress)
 	}
 	if gotStatusStr != expStatusStr {
 		t.Errorf("DB status mismatch: expected %s, got %s", expStatusStr, gotStatusStr)
@@ -335,8 +363,14 @@ func TestUpdateSessionResponse(t *testing.T) {
 		t.Errorf("DB number_in_cycle mismatch: expected %d, got %d", newNumberInCycle, gotNumberInCycle)
 	}
 	// Compare times in UTC with small tolerance
-	if gotEndTime.UTC().Before(endTime.UTC().Add(-time.Second)) || gotEndTime.UTC().After(endTime.UTC().Add(time.Second)) {
-		t.Errorf("DB end_time mismatch: expected ~%v, got %v", endTime, gotEndTime)
+	if gotEndTime.UTC().Before(et.Add(-time.Second)) || gotEndTime.UTC().After(et.Add(time.Second)) {
+		t.Errorf("DB end_time mismatch: expected ~%v, got %v", et, gotEndTime)
+	}
+
+	// Completed sessions cannot be rewritten, even without a status change.
+	req.Status = newStatus
+	if _, err := service.UpdateSession(context.Background(), req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition when updating a completed session, got %v", err)
 	}
 
 	// Clean up test session
@@ -350,7 +384,7 @@ func TestDeleteSession(t *testing.T) {
 	}
 	defer connections.Close()
 
-	service := NewService(connections)
+	service := newTestService(connections)
 
 	userId := uuid.NewString()
 	taskId := uuid.NewString()
@@ -385,3 +419,642 @@ func TestDeleteSession(t *testing.T) {
 		t.Errorf("Expected 0 sessions in the database after deletion, found %d", count)
 	}
 }
+
+func TestSessionLifecycle(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	sessionId := uuid.NewString()
+	startTime := time.Now()
+	SeedPomodoroSession(t, connections.PomodoroDB, sessionId, uuid.NewString(), uuid.NewString(), startTime, startTime.Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, sessionId)
+
+	started, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if started.Session.Status != pb.SessionStatus_SESSION_STATUS_IN_PROGRESS {
+		t.Errorf("Expected IN_PROGRESS after start, got %v", started.Session.Status)
+	}
+	if started.Session.Remaining <= 0 || started.Session.Remaining > started.Session.Duration {
+		t.Errorf("Expected remaining within (0, %d], got %d", started.Session.Duration, started.Session.Remaining)
+	}
+
+	paused, err := service.PauseSession(ctx, &pb.PauseSessionRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("PauseSession failed: %v", err)
+	}
+	if paused.Session.Status != pb.SessionStatus_SESSION_STATUS_PENDING || paused.Session.PausedAt == nil {
+		t.Errorf("Expected paused session with paused_at set, got status %v paused_at %v", paused.Session.Status, paused.Session.PausedAt)
+	}
+
+	// Starting a paused session is not the same as resuming it.
+	if _, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: sessionId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition when starting a paused session, got %v", err)
+	}
+
+	if _, err := service.ResumeSession(ctx, &pb.ResumeSessionRequest{SessionId: sessionId}); err != nil {
+		t.Fatalf("ResumeSession failed: %v", err)
+	}
+
+	completed, err := service.CompleteSession(ctx, &pb.CompleteSessionRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("CompleteSession failed: %v", err)
+	}
+	if completed.Session.Status != pb.SessionStatus_SESSION_STATUS_COMPLETED || completed.Session.Remaining != 0 {
+		t.Errorf("Expected COMPLETED with no time remaining, got %v with %d", completed.Session.Status, completed.Session.Remaining)
+	}
+
+	// Completed sessions are terminal.
+	if _, err := service.ResumeSession(ctx, &pb.ResumeSessionRequest{SessionId: sessionId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition when resuming a completed session, got %v", err)
+	}
+	_, err = service.UpdateSession(ctx, &pb.UpdateSessionRequest{
+		SessionId:  sessionId,
+		Status:     pb.SessionStatus_SESSION_STATUS_IN_PROGRESS,
+		EndTime:    timestamppb.Now(),
+		LastUpdate: timestamppb.Now(),
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition when reopening via UpdateSession, got %v", err)
+	}
+}
+
+func TestCreateSessionUsesSettings(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections, fakeSettings{settings: &userpb.Settings{
+		PomodoroDuration:   50,
+		ShortBreakDuration: 10,
+		LongBreakDuration:  30,
+	}}, nil)
+
+	cases := []struct {
+		sessionType pb.SessionType
+		wantType    pb.SessionType
+		want        time.Duration
+	}{
+		{pb.SessionType_SESSION_TYPE_UNSPECIFIED, pb.SessionType_SESSION_TYPE_FOCUS, 50 * time.Minute},
+		{pb.SessionType_SESSION_TYPE_FOCUS, pb.SessionType_SESSION_TYPE_FOCUS, 50 * time.Minute},
+		{pb.SessionType_SESSION_TYPE_SHORT_BREAK, pb.SessionType_SESSION_TYPE_SHORT_BREAK, 10 * time.Minute},
+		{pb.SessionType_SESSION_TYPE_LONG_BREAK, pb.SessionType_SESSION_TYPE_LONG_BREAK, 30 * time.Minute},
+	}
+	for _, c := range cases {
+		now := time.Now()
+		resp, err := service.CreateSession(context.Background(), &pb.CreateSessionRequest{
+			UserId:      uuid.NewString(),
+			TaskId:      uuid.NewString(),
+			StartTime:   timestamppb.New(now),
+			SessionType: c.sessionType,
+		})
+		if err != nil {
+			t.Fatalf("CreateSession(%v) failed: %v", c.sessionType, err)
+		}
+		RemovePomodoroSession(connections, resp.Session.SessionId)
+
+		if resp.Session.SessionType != c.wantType {
+			t.Errorf("Expected session type %v, got %v", c.wantType, resp.Session.SessionType)
+		}
+		if got := time.Duration(resp.Session.Duration) * time.Second; got != c.want {
+			t.Errorf("%v: expected duration %v, got %v", c.sessionType, c.want, got)
+		}
+		if got := resp.Session.EndTime.AsTime().Sub(resp.Session.StartTime.AsTime()); got != c.want {
+			t.Errorf("%v: expected end time %v after start, got %v", c.sessionType, c.want, got)
+		}
+	}
+}
+
+func TestCompleteSessionCreditsTask(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	taskId := uuid.NewString()
+	now := time.Now()
+	_, err = connections.TaskDB.Exec(`
+        INSERT INTO tasks (
+            task_id, user_id, group_id, icon, name, description,
+            priority, status, total_pomodoros, completed_pomodoros, progress,
+            deadline, created_at, updated_at
+        ) VALUES ($1,$2,$3,'','Credit test','','medium','in progress',1,0,0,NULL,$4,$4)`,
+		taskId, userId, uuid.NewString(), now)
+	if err != nil {
+		t.Fatalf("Failed to seed task: %v", err)
+	}
+	defer connections.TaskDB.Exec("DELETE FROM tasks WHERE task_id = $1", taskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", taskId)
+
+	created, err := service.CreateSession(ctx, &pb.CreateSessionRequest{
+		UserId:      userId,
+		TaskId:      taskId,
+		SessionType: pb.SessionType_SESSION_TYPE_FOCUS,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	sessionId := created.Session.SessionId
+	defer RemovePomodoroSession(connections, sessionId)
+
+	if _, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: sessionId}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	completed, err := service.CompleteSession(ctx, &pb.CompleteSessionRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("CompleteSession failed: %v", err)
+	}
+
+	// A retry must not credit the same session twice.
+	if err := service.tasks.CreditPomodoro(ctx, sessionId, taskId, 25*time.Minute); err != nil {
+		t.Fatalf("CreditPomodoro retry failed: %v", err)
+	}
+	service.creditTask(ctx, completed.Session)
+
+	var (
+		completedPomodoros, progress int32
+		statusStr                    string
+		creditedAt                   sql.NullTime
+	)
+	err = connections.TaskDB.QueryRow(
+		"SELECT completed_pomodoros, progress, status FROM tasks WHERE task_id = $1", taskId,
+	).Scan(&completedPomodoros, &progress, &statusStr)
+	if err != nil {
+		t.Fatalf("Failed to query credited task: %v", err)
+	}
+	if completedPomodoros != 1 || progress != 100 || statusStr != "completed" {
+		t.Errorf("Expected 1 pomodoro, 100%% progress and completed status, got %d, %d%%, %q",
+			completedPomodoros, progress, statusStr)
+	}
+
+	err = connections.PomodoroDB.QueryRow(
+		"SELECT task_credited_at FROM sessions WHERE session_id = $1", sessionId,
+	).Scan(&creditedAt)
+	if err != nil {
+		t.Fatalf("Failed to query session credit marker: %v", err)
+	}
+	if !creditedAt.Valid {
+		t.Error("Expected task_credited_at to be set after completion")
+	}
+}
+
+func TestInterruptions(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	sessionId := uuid.NewString()
+	startTime := time.Now()
+	SeedPomodoroSession(t, connections.PomodoroDB, sessionId, uuid.NewString(), uuid.NewString(), startTime, startTime.Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, sessionId)
+
+	if _, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: sessionId}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	if _, err := service.CreateInterruption(ctx, &pb.CreateInterruptionRequest{SessionId: sessionId}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without kind, got %v", err)
+	}
+
+	internal, err := service.CreateInterruption(ctx, &pb.CreateInterruptionRequest{
+		SessionId: sessionId,
+		Kind:      pb.InterruptionKind_INTERRUPTION_KIND_INTERNAL,
+		Note:      "checked email",
+	})
+	if err != nil {
+		t.Fatalf("CreateInterruption failed: %v", err)
+	}
+	if internal.Session.Status != pb.SessionStatus_SESSION_STATUS_IN_PROGRESS {
+		t.Errorf("Expected session to keep running, got %v", internal.Session.Status)
+	}
+
+	external, err := service.CreateInterruption(ctx, &pb.CreateInterruptionRequest{
+		SessionId:   sessionId,
+		Kind:        pb.InterruptionKind_INTERRUPTION_KIND_EXTERNAL,
+		Note:        "phone call",
+		PausedTimer: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateInterruption failed: %v", err)
+	}
+	if external.Session.Status != pb.SessionStatus_SESSION_STATUS_PENDING || !external.Interruption.PausedTimer {
+		t.Errorf("Expected session to be paused, got %v", external.Session.Status)
+	}
+
+	// The session is already paused, so this interruption pauses nothing.
+	again, err := service.CreateInterruption(ctx, &pb.CreateInterruptionRequest{
+		SessionId:   sessionId,
+		Kind:        pb.InterruptionKind_INTERRUPTION_KIND_EXTERNAL,
+		PausedTimer: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateInterruption failed: %v", err)
+	}
+	if again.Interruption.PausedTimer {
+		t.Error("Expected paused_timer to be false when the session was not running")
+	}
+
+	got, err := service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.InternalInterruptions != 1 || got.Session.ExternalInterruptions != 2 {
+		t.Errorf("Expected 1 internal and 2 external interruptions, got %d and %d",
+			got.Session.InternalInterruptions, got.Session.ExternalInterruptions)
+	}
+
+	list, err := service.GetInterruptions(ctx, &pb.GetInterruptionsRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("GetInterruptions failed: %v", err)
+	}
+	if len(list.Interruptions) != 3 {
+		t.Fatalf("Expected 3 interruptions, got %d", len(list.Interruptions))
+	}
+	if list.Interruptions[2].PausedTimer {
+		t.Error("Expected the stored interruption to record that nothing was paused")
+	}
+
+	_, err = service.DeleteInterruption(ctx, &pb.DeleteInterruptionRequest{
+		SessionId:      sessionId,
+		InterruptionId: internal.Interruption.InterruptionId,
+	})
+	if err != nil {
+		t.Fatalf("DeleteInterruption failed: %v", err)
+	}
+
+	got, err = service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.InternalInterruptions != 0 || got.Session.ExternalInterruptions != 2 {
+		t.Errorf("Expected 0 internal and 2 external interruptions after delete, got %d and %d",
+			got.Session.InternalInterruptions, got.Session.ExternalInterruptions)
+	}
+}
+
+func TestReflections(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	taskId := uuid.NewString()
+	created, err := service.CreateSession(ctx, &pb.CreateSessionRequest{
+		UserId:      userId,
+		TaskId:      taskId,
+		SessionType: pb.SessionType_SESSION_TYPE_FOCUS,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	sessionId := created.Session.SessionId
+	defer RemovePomodoroSession(connections, sessionId)
+
+	if _, err := service.SaveReflection(ctx, &pb.SaveReflectionRequest{SessionId: sessionId, Rating: 6}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for rating 6, got %v", err)
+	}
+
+	if _, err := service.SaveReflection(ctx, &pb.SaveReflectionRequest{
+		SessionId: sessionId,
+		Note:      "Drafted the API",
+		Rating:    3,
+		Mood:      " Tired ",
+	}); err != nil {
+		t.Fatalf("SaveReflection failed: %v", err)
+	}
+	saved, err := service.SaveReflection(ctx, &pb.SaveReflectionRequest{
+		SessionId: sessionId,
+		Note:      "Drafted and reviewed the API",
+		Rating:    4,
+		Mood:      "energized",
+	})
+	if err != nil {
+		t.Fatalf("SaveReflection (replace) failed: %v", err)
+	}
+	if saved.Reflection.Rating != 4 || saved.Reflection.TaskId != taskId || saved.Reflection.UserId != userId {
+		t.Errorf("Unexpected reflection after replace: %+v", saved.Reflection)
+	}
+
+	start := created.Session.StartTime.AsTime()
+	list, err := service.GetReflections(ctx, &pb.GetReflectionsRequest{
+		UserId:    userId,
+		TaskId:    taskId,
+		StartFrom: timestamppb.New(start.Add(-time.Minute)),
+		StartTo:   timestamppb.New(start.Add(time.Minute)),
+	})
+	if err != nil {
+		t.Fatalf("GetReflections failed: %v", err)
+	}
+	if len(list.Reflections) != 1 || list.Reflections[0].Mood != "energized" {
+		t.Errorf("Expected the replaced reflection, got %+v", list.Reflections)
+	}
+
+	list, err = service.GetReflections(ctx, &pb.GetReflectionsRequest{
+		UserId:    userId,
+		StartFrom: timestamppb.New(start.Add(time.Minute)),
+	})
+	if err != nil {
+		t.Fatalf("GetReflections failed: %v", err)
+	}
+	if len(list.Reflections) != 0 {
+		t.Errorf("Expected no reflections after the session start, got %d", len(list.Reflections))
+	}
+}
+
+func TestGetSessionsPagination(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	taskId := uuid.NewString()
+	otherTaskId := uuid.NewString()
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	var sessionIds []string
+	for i := 0; i < 5; i++ {
+		sessionId := uuid.NewString()
+		start := base.Add(time.Duration(i) * time.Hour)
+		SeedPomodoroSession(t, connections.PomodoroDB, sessionId, userId, taskId, start, start.Add(25*time.Minute))
+		defer RemovePomodoroSession(connections, sessionId)
+		sessionIds = append(sessionIds, sessionId)
+	}
+	otherId := uuid.NewString()
+	SeedPomodoroSession(t, connections.PomodoroDB, otherId, userId, otherTaskId, base, base.Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, otherId)
+
+	// Walk all pages oldest first, filtered to one task.
+	var got []string
+	req := &pb.GetSessionsRequest{UserId: userId, TaskId: taskId, PageSize: 2, Order: pb.SortOrder_SORT_ORDER_ASC}
+	for {
+		resp, err := service.GetSessions(ctx, req)
+		if err != nil {
+			t.Fatalf("GetSessions failed: %v", err)
+		}
+		for _, session := range resp.Sessions {
+			got = append(got, session.SessionId)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if !slices.Equal(got, sessionIds) {
+		t.Errorf("Expected sessions %v in order, got %v", sessionIds, got)
+	}
+
+	// Newest first within a start_time window.
+	resp, err := service.GetSessions(ctx, &pb.GetSessionsRequest{
+		UserId:    userId,
+		TaskId:    taskId,
+		StartFrom: timestamppb.New(base.Add(time.Hour)),
+		StartTo:   timestamppb.New(base.Add(3 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+	if len(resp.Sessions) != 2 || resp.Sessions[0].SessionId != sessionIds[2] || resp.NextPageToken != "" {
+		t.Errorf("Expected sessions 2 and 1 newest first on a single page, got %v", resp.Sessions)
+	}
+
+	resp, err = service.GetSessions(ctx, &pb.GetSessionsRequest{
+		UserId:   userId,
+		Statuses: []pb.SessionStatus{pb.SessionStatus_SESSION_STATUS_COMPLETED},
+	})
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+	if len(resp.Sessions) != 0 {
+		t.Errorf("Expected no completed sessions, got %d", len(resp.Sessions))
+	}
+
+	if _, err := service.GetSessions(ctx, &pb.GetSessionsRequest{UserId: userId, PageToken: "bogus"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad page token, got %v", err)
+	}
+}
+
+func TestReapStaleSessions(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	sessionId := uuid.NewString()
+	startTime := time.Now()
+	SeedPomodoroSession(t, connections.PomodoroDB, sessionId, uuid.NewString(), uuid.NewString(), startTime, startTime.Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, sessionId)
+
+	if _, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: sessionId}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	// A fresh session is left alone.
+	service.reapStaleSessions(ctx, time.Hour)
+	got, err := service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.Status != pb.SessionStatus_SESSION_STATUS_IN_PROGRESS {
+		t.Fatalf("Expected fresh session to stay IN_PROGRESS, got %v", got.Session.Status)
+	}
+
+	// Not updated for longer than the threshold with time left on the clock: abandoned.
+	leftId := uuid.NewString()
+	SeedPomodoroSession(t, connections.PomodoroDB, leftId, uuid.NewString(), uuid.NewString(), startTime, startTime.Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, leftId)
+	if _, err := service.StartSession(ctx, &pb.StartSessionRequest{SessionId: leftId}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := connections.PomodoroDB.Exec(
+		"UPDATE sessions SET last_update = $1 WHERE session_id = $2", time.Now().Add(-3*time.Hour), leftId); err != nil {
+		t.Fatalf("Failed to age last_update: %v", err)
+	}
+	service.reapStaleSessions(ctx, time.Hour)
+	got, err = service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: leftId})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.Status != pb.SessionStatus_SESSION_STATUS_ABANDONED {
+		t.Errorf("Expected a stale session with time left to be ABANDONED, got %v", got.Session.Status)
+	}
+
+	// Pretend the client went away three hours ago, right after starting.
+	stale := time.Now().Add(-3 * time.Hour)
+	_, err = connections.PomodoroDB.Exec(
+		"UPDATE sessions SET start_time = $1, end_time = $2, last_update = $1 WHERE session_id = $3",
+		stale, stale.Add(25*time.Minute), sessionId)
+	if err != nil {
+		t.Fatalf("Failed to age session: %v", err)
+	}
+
+	service.reapStaleSessions(ctx, time.Hour)
+	got, err = service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: sessionId})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.Status != pb.SessionStatus_SESSION_STATUS_COMPLETED {
+		t.Errorf("Expected stale run-out session to be COMPLETED, got %v", got.Session.Status)
+	}
+	if got.Session.Progress != got.Session.Duration {
+		t.Errorf("Expected progress %d, got %d", got.Session.Duration, got.Session.Progress)
+	}
+}
+
+func TestImportSessions(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	taskId := uuid.NewString()
+	base := time.Now().Add(-6 * time.Hour).Truncate(time.Second)
+	item := func(id string, start time.Time) *pb.ImportedSession {
+		return &pb.ImportedSession{
+			SessionId:   id,
+			TaskId:      taskId,
+			SessionType: pb.SessionType_SESSION_TYPE_SHORT_BREAK,
+			StartTime:   timestamppb.New(start),
+			EndTime:     timestamppb.New(start.Add(25 * time.Minute)),
+		}
+	}
+
+	first := uuid.NewString()
+	second := uuid.NewString()
+	overlapping := uuid.NewString()
+	defer RemovePomodoroSession(connections, first)
+	defer RemovePomodoroSession(connections, second)
+	defer RemovePomodoroSession(connections, overlapping)
+
+	resp, err := service.ImportSessions(ctx, &pb.ImportSessionsRequest{
+		UserId: userId,
+		Sessions: []*pb.ImportedSession{
+			item(first, base),
+			item(second, base.Add(time.Hour)),
+			item(overlapping, base.Add(10*time.Minute)),
+			item(first, base),
+			item("not-a-uuid", base.Add(2*time.Hour)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportSessions failed: %v", err)
+	}
+
+	want := []pb.ImportResultStatus{
+		pb.ImportResultStatus_IMPORT_RESULT_STATUS_ACCEPTED,
+		pb.ImportResultStatus_IMPORT_RESULT_STATUS_ACCEPTED,
+		pb.ImportResultStatus_IMPORT_RESULT_STATUS_REJECTED,
+		pb.ImportResultStatus_IMPORT_RESULT_STATUS_DUPLICATE,
+		pb.ImportResultStatus_IMPORT_RESULT_STATUS_REJECTED,
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(resp.Results))
+	}
+	for i, result := range resp.Results {
+		if result.Status != want[i] {
+			t.Errorf("Result %d: expected %v, got %v (%s)", i, want[i], result.Status, result.Reason)
+		}
+	}
+	if resp.Accepted != 2 {
+		t.Errorf("Expected 2 accepted sessions, got %d", resp.Accepted)
+	}
+
+	// Re-uploading the same batch is a no-op.
+	resp, err = service.ImportSessions(ctx, &pb.ImportSessionsRequest{
+		UserId:   userId,
+		Sessions: []*pb.ImportedSession{item(first, base)},
+	})
+	if err != nil {
+		t.Fatalf("ImportSessions retry failed: %v", err)
+	}
+	if resp.Results[0].Status != pb.ImportResultStatus_IMPORT_RESULT_STATUS_DUPLICATE {
+		t.Errorf("Expected DUPLICATE on retry, got %v", resp.Results[0].Status)
+	}
+
+	got, err := service.GetSessionById(ctx, &pb.GetSessionByIdRequest{SessionId: first})
+	if err != nil {
+		t.Fatalf("GetSessionById failed: %v", err)
+	}
+	if got.Session.Status != pb.SessionStatus_SESSION_STATUS_COMPLETED || got.Session.Progress != 25*60 {
+		t.Errorf("Expected completed session with 1500s progress, got %v with %d", got.Session.Status, got.Session.Progress)
+	}
+}
+
+func TestUpdateSessionPausesThroughTimer(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := newTestService(connections)
+	ctx := context.Background()
+
+	sessionId := uuid.NewString()
+	SeedPomodoroSession(t, connections.PomodoroDB, sessionId, uuid.NewString(), uuid.NewString(), time.Now(), time.Now().Add(25*time.Minute))
+	defer RemovePomodoroSession(connections, sessionId)
+
+	started, err := service.UpdateSession(ctx, &pb.UpdateSessionRequest{SessionId: sessionId, Status: pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, NumberInCycle: 3})
+	if err != nil {
+		t.Fatalf("UpdateSession to IN_PROGRESS failed: %v", err)
+	}
+	if time.Since(started.Session.StartTime.AsTime()) > 5*time.Second {
+		t.Errorf("Expected starting to reset start_time, got %v", started.Session.StartTime.AsTime())
+	}
+
+	paused, err := service.UpdateSession(ctx, &pb.UpdateSessionRequest{SessionId: sessionId, Status: pb.SessionStatus_SESSION_STATUS_PENDING})
+	if err != nil {
+		t.Fatalf("UpdateSession to PENDING failed: %v", err)
+	}
+	if paused.Session.PausedAt == nil {
+		t.Fatal("Expected pausing through UpdateSession to set paused_at")
+	}
+	if paused.Session.NumberInCycle != 3 {
+		t.Errorf("Expected a status-only update to keep number_in_cycle 3, got %d", paused.Session.NumberInCycle)
+	}
+
+	var pausedAt sql.NullTime
+	if err := connections.PomodoroDB.QueryRow(`SELECT paused_at FROM sessions WHERE session_id = $1`, sessionId).Scan(&pausedAt); err != nil {
+		t.Fatalf("Failed to query paused session: %v", err)
+	}
+	if !pausedAt.Valid {
+		t.Error("Expected paused_at to be stored")
+	}
+}
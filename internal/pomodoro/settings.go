@@ -0,0 +1,102 @@
+/*
+File: internal/pomodoro/settings.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Access to the user's pomodoro settings owned by the user service.
+*/
+
+package pomodoro
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	userpb "github.com/latrung124/Totodoro-Backend/internal/proto_package/user_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SettingsProvider returns a user's settings. *user.Service satisfies it.
+type SettingsProvider interface {
+	GetSettings(ctx context.Context, req *userpb.GetSettingsRequest) (*userpb.GetSettingsResponse, error)
+}
+
+// userSettings is the subset of user settings that drives the pomodoro timer.
+type userSettings struct {
+	pomodoroDuration    time.Duration
+	shortBreakDuration  time.Duration
+	longBreakDuration   time.Duration
+	pomodoroInterval    int32
+	autoStartPomodoro   bool
+	autoStartShortBreak bool
+	autoStartLongBreak  bool
+}
+
+// defaultSettings mirrors the defaults the user service creates for new users.
+var defaultSettings = userSettings{
+	pomodoroDuration:   25 * time.Minute,
+	shortBreakDuration: 5 * time.Minute,
+	longBreakDuration:  15 * time.Minute,
+	pomodoroInterval:   4,
+}
+
+// loadSettings fetches the user's settings, falling back to defaults when none are stored.
+func (s *Service) loadSettings(ctx context.Context, userID string) (userSettings, error) {
+	if s.settings == nil {
+		return defaultSettings, nil
+	}
+
+	resp, err := s.settings.GetSettings(ctx, &userpb.GetSettingsRequest{UserId: userID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return defaultSettings, nil
+		}
+		return userSettings{}, err
+	}
+
+	st := resp.GetSettings()
+	settings := defaultSettings
+	// Durations are stored in minutes; ignore values that cannot drive a timer.
+	if st.GetPomodoroDuration() > 0 {
+		settings.pomodoroDuration = time.Duration(st.GetPomodoroDuration()) * time.Minute
+	}
+	if st.GetShortBreakDuration() > 0 {
+		settings.shortBreakDuration = time.Duration(st.GetShortBreakDuration()) * time.Minute
+	}
+	if st.GetLongBreakDuration() > 0 {
+		settings.longBreakDuration = time.Duration(st.GetLongBreakDuration()) * time.Minute
+	}
+	if st.GetPomodoroInterval() > 0 {
+		settings.pomodoroInterval = st.GetPomodoroInterval()
+	}
+	settings.autoStartPomodoro = st.GetAutoStartPomodoro()
+	settings.autoStartShortBreak = st.GetAutoStartShortBreak()
+	settings.autoStartLongBreak = st.GetAutoStartLongBreak()
+	return settings, nil
+}
+
+// durationFor returns the planned length of a session of the given type.
+func (u userSettings) durationFor(sessionType pb.SessionType) time.Duration {
+	switch sessionType {
+	case pb.SessionType_SESSION_TYPE_SHORT_BREAK:
+		return u.shortBreakDuration
+	case pb.SessionType_SESSION_TYPE_LONG_BREAK:
+		return u.longBreakDuration
+	default:
+		return u.pomodoroDuration
+	}
+}
+
+// autoStartFor reports whether a session of the given type should start as soon as it is created.
+func (u userSettings) autoStartFor(sessionType pb.SessionType) bool {
+	switch sessionType {
+	case pb.SessionType_SESSION_TYPE_SHORT_BREAK:
+		return u.autoStartShortBreak
+	case pb.SessionType_SESSION_TYPE_LONG_BREAK:
+		return u.autoStartLongBreak
+	default:
+		return u.autoStartPomodoro
+	}
+}
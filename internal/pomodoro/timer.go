@@ -0,0 +1,147 @@
+/*
+File: internal/pomodoro/timer.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/pomodoro
+Description: Server-side timer state machine for pomodoro sessions.
+*/
+
+package pomodoro
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errIllegalTransition = errors.New("illegal session status transition")
+
+// transitions lists the statuses each status may move to.
+// SESSION_STATUS_PENDING is the paused state.
+var transitions = map[pb.SessionStatus][]pb.SessionStatus{
+	pb.SessionStatus_SESSION_STATUS_IDLE: {
+		pb.SessionStatus_SESSION_STATUS_IN_PROGRESS,
+		pb.SessionStatus_SESSION_STATUS_ABANDONED,
+	},
+	pb.SessionStatus_SESSION_STATUS_IN_PROGRESS: {
+		pb.SessionStatus_SESSION_STATUS_PENDING,
+		pb.SessionStatus_SESSION_STATUS_COMPLETED,
+		pb.SessionStatus_SESSION_STATUS_ABANDONED,
+	},
+	pb.SessionStatus_SESSION_STATUS_PENDING: {
+		pb.SessionStatus_SESSION_STATUS_IN_PROGRESS,
+		pb.SessionStatus_SESSION_STATUS_COMPLETED,
+		pb.SessionStatus_SESSION_STATUS_ABANDONED,
+	},
+}
+
+// canTransition reports whether a session may move from one status to another.
+func canTransition(from, to pb.SessionStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isTerminal reports whether no further transitions are possible from status.
+func isTerminal(status pb.SessionStatus) bool {
+	return len(transitions[status]) == 0
+}
+
+// timer holds the persisted clock state of a session.
+type timer struct {
+	status         pb.SessionStatus
+	start          time.Time
+	end            time.Time
+	progress       time.Duration
+	duration       time.Duration
+	pausedAt       sql.NullTime
+	pausedDuration time.Duration
+}
+
+// elapsed returns the focused time at now: wall time since start minus time spent paused.
+func (t *timer) elapsed(now time.Time) time.Duration {
+	switch t.status {
+	case pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, pb.SessionStatus_SESSION_STATUS_PENDING:
+	default:
+		return t.progress
+	}
+
+	ref := now
+	if t.pausedAt.Valid {
+		ref = t.pausedAt.Time
+	}
+	e := ref.Sub(t.start) - t.pausedDuration
+	if e < 0 {
+		return 0
+	}
+	if e > t.duration {
+		return t.duration
+	}
+	return e
+}
+
+// remaining returns how much of the planned duration is left at now.
+func (t *timer) remaining(now time.Time) time.Duration {
+	if isTerminal(t.status) {
+		return 0
+	}
+	return t.duration - t.elapsed(now)
+}
+
+// transition moves the timer to status at now, updating the clock fields accordingly.
+func (t *timer) transition(to pb.SessionStatus, now time.Time) error {
+	if !canTransition(t.status, to) {
+		return fmt.Errorf("%w: %s -> %s", errIllegalTransition, t.status, to)
+	}
+
+	switch to {
+	case pb.SessionStatus_SESSION_STATUS_IN_PROGRESS:
+		if t.status == pb.SessionStatus_SESSION_STATUS_IDLE {
+			// Starting: the clock begins now regardless of the planned start.
+			t.start = now
+			t.pausedDuration = 0
+		} else {
+			// Resuming: fold the pause into the accumulated paused time.
+			t.pausedDuration += now.Sub(t.pausedAt.Time).Truncate(time.Second)
+			t.pausedAt = sql.NullTime{}
+		}
+		t.end = t.start.Add(t.pausedDuration + t.duration)
+	case pb.SessionStatus_SESSION_STATUS_PENDING:
+		t.progress = t.elapsed(now)
+		t.pausedAt = sql.NullTime{Time: now, Valid: true}
+	case pb.SessionStatus_SESSION_STATUS_COMPLETED, pb.SessionStatus_SESSION_STATUS_ABANDONED:
+		t.progress = t.elapsed(now)
+		if t.pausedAt.Valid {
+			t.pausedDuration += now.Sub(t.pausedAt.Time).Truncate(time.Second)
+			t.pausedAt = sql.NullTime{}
+		}
+		if t.status != pb.SessionStatus_SESSION_STATUS_IDLE {
+			t.end = now
+		}
+	}
+
+	t.status = to
+	return nil
+}
+
+// fill copies the timer state, plus the values derived from it at now, into session.
+func (t *timer) fill(session *pb.PomodoroSession, now time.Time) {
+	session.Status = t.status
+	session.StartTime = timestamppb.New(t.start)
+	session.EndTime = timestamppb.New(t.end)
+	session.Progress = int32(t.elapsed(now) / time.Second)
+	session.Duration = int32(t.duration / time.Second)
+	session.Remaining = int32(t.remaining(now) / time.Second)
+	session.PausedDuration = int32(t.pausedDuration / time.Second)
+	session.PausedAt = nil
+	if t.pausedAt.Valid {
+		session.PausedAt = timestamppb.New(t.pausedAt.Time)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+File: internal/pomodoro/timer_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the session timer state machine.
+*/
+
+package pomodoro
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/pomodoro_service"
+)
+
+func TestTimerPauseResume(t *testing.T) {
+	t0 := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	tm := &timer{status: pb.SessionStatus_SESSION_STATUS_IDLE, duration: 25 * time.Minute}
+
+	if err := tm.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if err := tm.transition(pb.SessionStatus_SESSION_STATUS_PENDING, t0.Add(10*time.Minute)); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+
+	// Time does not advance while paused.
+	if got := tm.elapsed(t0.Add(20 * time.Minute)); got != 10*time.Minute {
+		t.Errorf("Expected 10m elapsed while paused, got %v", got)
+	}
+
+	if err := tm.transition(pb.SessionStatus_SESSION_STATUS_IN_PROGRESS, t0.Add(15*time.Minute)); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if got := tm.remaining(t0.Add(20 * time.Minute)); got != 10*time.Minute {
+		t.Errorf("Expected 10m remaining after a 5m pause, got %v", got)
+	}
+	if want := t0.Add(30 * time.Minute); !tm.end.Equal(want) {
+		t.Errorf("Expected end time %v, got %v", want, tm.end)
+	}
+
+	// Elapsed never exceeds the planned duration.
+	if got := tm.elapsed(t0.Add(time.Hour)); got != tm.duration {
+		t.Errorf("Expected elapsed capped at %v, got %v", tm.duration, got)
+	}
+}
+
+func TestTimerIllegalTransitions(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		from, to pb.SessionStatus
+	}{
+		{pb.SessionStatus_SESSION_STATUS_COMPLETED, pb.SessionStatus_SESSION_STATUS_IN_PROGRESS},
+		{pb.SessionStatus_SESSION_STATUS_ABANDONED, pb.SessionStatus_SESSION_STATUS_IN_PROGRESS},
+		{pb.SessionStatus_SESSION_STATUS_IDLE, pb.SessionStatus_SESSION_STATUS_COMPLETED},
+		{pb.SessionStatus_SESSION_STATUS_IDLE, pb.SessionStatus_SESSION_STATUS_PENDING},
+		{pb.SessionStatus_SESSION_STATUS_PENDING, pb.SessionStatus_SESSION_STATUS_PENDING},
+	}
+	for _, c := range cases {
+		tm := &timer{status: c.from, duration: 25 * time.Minute}
+		if err := tm.transition(c.to, now); !errors.Is(err, errIllegalTransition) {
+			t.Errorf("%s -> %s: expected errIllegalTransition, got %v", c.from, c.to, err)
+		}
+	}
+}
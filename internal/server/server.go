@@ -11,6 +11,7 @@ import (
 	"context"
 	"log"
 	"net"
+	"time"
 
 	"github.com/latrung124/Totodoro-Backend/internal/config"
 	"github.com/latrung124/Totodoro-Backend/internal/database"
@@ -64,9 +65,9 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Construct service implementations once (they can share DB connections)
 	userService := user.NewService(connections)
-	pomodoroService := pomodoro.NewService(connections)
-	statisticService := statistic.NewService(connections)
 	taskmanagerService := task_management.NewService(connections)
+	pomodoroService := pomodoro.NewService(connections, userService, taskmanagerService)
+	statisticService := statistic.NewService(connections)
 	notificationService := notification.NewService(connections)
 
 	// Build listen addresses with host + port
@@ -107,6 +108,12 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Background workers stop when ctx is cancelled.
+	go pomodoroService.RunTaskCreditRetries(ctx, time.Minute)
+	go pomodoroService.RunStaleSessionReaper(ctx, cfg.StaleSessionThreshold, cfg.StaleSessionReapInterval)
+	go taskmanagerService.RunRecurrenceScheduler(ctx, 15*time.Minute)
+	go taskmanagerService.RunTrashRetention(ctx, cfg.TrashRetention, time.Hour)
+
 	// All services started asynchronously; return to caller.
 	log.Printf("All gRPC services started: user:%s pomodoro:%s statistic:%s task:%s notification:%s",
 		userAddr, pomodoroAddr, statisticAddr, taskAddr, notificationAddr)
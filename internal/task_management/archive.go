@@ -0,0 +1,69 @@
+/*
+File: internal/task_management/archive.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Archiving task groups, which hides them and their tasks from lists.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ArchiveTaskGroup hides a group and its tasks from lists that do not ask for archived
+// groups. Archiving an archived group keeps its original archived_at.
+func (s *Service) ArchiveTaskGroup(ctx context.Context, req *pb.ArchiveTaskGroupRequest) (*pb.ArchiveTaskGroupResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	group, err := s.setGroupArchived(ctx, req.GroupId, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task group not found")
+		}
+		log.Printf("Error archiving task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to archive task group")
+	}
+
+	return &pb.ArchiveTaskGroupResponse{Group: group}, nil
+}
+
+func (s *Service) UnarchiveTaskGroup(ctx context.Context, req *pb.UnarchiveTaskGroupRequest) (*pb.UnarchiveTaskGroupResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	group, err := s.setGroupArchived(ctx, req.GroupId, false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task group not found")
+		}
+		log.Printf("Error unarchiving task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to unarchive task group")
+	}
+
+	return &pb.UnarchiveTaskGroupResponse{Group: group}, nil
+}
+
+// setGroupArchived sets or clears archived_at of a group outside the trash and returns
+// the group. It returns sql.ErrNoRows if there is no such group.
+func (s *Service) setGroupArchived(ctx context.Context, groupID string, archived bool) (*pb.TaskGroup, error) {
+	now := time.Now()
+	return scanTaskGroup(s.db.TaskDB.QueryRowContext(ctx, `
+		UPDATE task_groups SET
+			archived_at = CASE WHEN $1 THEN COALESCE(archived_at, $2) END,
+			updated_at = $2
+		WHERE group_id = $3 AND deleted_at IS NULL
+		RETURNING `+taskGroupColumns,
+		archived, now, groupID))
+}
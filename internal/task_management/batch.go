@@ -0,0 +1,248 @@
+/*
+File: internal/task_management/batch.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Batch task operations: moving tasks between groups and bulk updates.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatchSize caps the number of tasks in one batch request.
+const maxBatchSize = 100
+
+// batchChange is the change a batch applies to each of its tasks. Zero fields are left unchanged.
+type batchChange struct {
+	status        pb.TaskStatus
+	priority      pb.TaskPriority
+	deadline      *timestamppb.Timestamp
+	clearDeadline bool
+	groupID       string
+	force         bool
+}
+
+// MoveTasks moves tasks to another group of the same user, appending them to its order.
+func (s *Service) MoveTasks(ctx context.Context, req *pb.MoveTasksRequest) (*pb.MoveTasksResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	results, err := s.applyBatch(ctx, req.TaskIds, batchChange{groupID: req.GroupId})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.MoveTasksResponse{Results: results}, nil
+}
+
+func (s *Service) BatchUpdateTasks(ctx context.Context, req *pb.BatchUpdateTasksRequest) (*pb.BatchUpdateTasksResponse, error) {
+	if req.Deadline != nil && req.ClearDeadline {
+		return nil, status.Error(codes.InvalidArgument, "deadline and clear_deadline cannot both be set")
+	}
+
+	results, err := s.applyBatch(ctx, req.TaskIds, batchChange{
+		status:        req.Status,
+		priority:      req.Priority,
+		deadline:      req.Deadline,
+		clearDeadline: req.ClearDeadline,
+		groupID:       req.GroupId,
+		force:         req.Force,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BatchUpdateTasksResponse{Results: results}, nil
+}
+
+// lockTasks locks the rows of the given tasks in task_id order, whatever order the
+// IDs come in, so overlapping batches cannot deadlock on each other.
+func lockTasks(ctx context.Context, tx *sql.Tx, taskIDs []string) error {
+	_, err := tx.ExecContext(ctx, `
+		SELECT 1 FROM tasks
+		WHERE task_id = ANY($1::uuid[])
+		ORDER BY task_id
+		FOR UPDATE`, pq.Array(taskIDs))
+	return err
+}
+
+// applyBatch applies change to each task in one transaction. Tasks that cannot be
+// changed get an error result and are skipped; database failures abort the whole batch.
+// Like every other write, it locks task rows before the group's rank lock.
+func (s *Service) applyBatch(ctx context.Context, taskIDs []string, change batchChange) ([]*pb.TaskBatchResult, error) {
+	if len(taskIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "task_ids is required")
+	}
+	if len(taskIDs) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d tasks can be changed at once", maxBatchSize)
+	}
+	if change.groupID != "" {
+		if _, err := uuid.Parse(change.groupID); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "group_id must be a valid UUID")
+		}
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting batch task update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update tasks")
+	}
+	defer tx.Rollback()
+
+	var valid []string
+	for _, taskID := range taskIDs {
+		if _, err := uuid.Parse(taskID); err == nil {
+			valid = append(valid, taskID)
+		}
+	}
+	if err := lockTasks(ctx, tx, valid); err != nil {
+		log.Printf("Error locking tasks for batch update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update tasks")
+	}
+
+	var (
+		groupUserID string
+		ranks       rankList
+	)
+	if change.groupID != "" {
+		err := tx.QueryRowContext(ctx,
+			"SELECT user_id FROM task_groups WHERE group_id = $1 AND deleted_at IS NULL", change.groupID,
+		).Scan(&groupUserID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, status.Error(codes.NotFound, "task group not found")
+			}
+			log.Printf("Error fetching target task group: %v", err)
+			return nil, status.Error(codes.Internal, "failed to update tasks")
+		}
+		ranks = taskRankList(change.groupID)
+		if err := ranks.lock(ctx, tx); err != nil {
+			log.Printf("Error locking task order: %v", err)
+			return nil, status.Error(codes.Internal, "failed to update tasks")
+		}
+	}
+
+	now := time.Now()
+	results := make([]*pb.TaskBatchResult, len(taskIDs))
+	var (
+		touchedGroups []string
+		completed     []string
+	)
+	for i, taskID := range taskIDs {
+		result := &pb.TaskBatchResult{TaskId: taskID}
+		results[i] = result
+
+		if _, err := uuid.Parse(taskID); err != nil {
+			setBatchError(result, status.Error(codes.InvalidArgument, "task_id must be a valid UUID"))
+			continue
+		}
+		current, err := getTask(ctx, tx, taskID, false)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				setBatchError(result, status.Error(codes.NotFound, "task not found"))
+				continue
+			}
+			log.Printf("Error fetching task %s for batch update: %v", taskID, err)
+			return nil, status.Error(codes.Internal, "failed to update tasks")
+		}
+
+		// Check everything before writing, so a skipped task is left untouched.
+		move := change.groupID != "" && current.GroupId != change.groupID
+		if move && current.UserId != groupUserID {
+			setBatchError(result, status.Error(codes.InvalidArgument, "task belongs to another user than the group"))
+			continue
+		}
+		if change.status != pb.TaskStatus_TASK_STATUS_UNSPECIFIED {
+			if err := checkStartAllowed(current, change.status, change.force); err != nil {
+				setBatchError(result, err)
+				continue
+			}
+		}
+
+		var args queryArgs
+		nowArg := args.add(now)
+		set := `updated_at = ` + nowArg
+		if change.status != pb.TaskStatus_TASK_STATUS_UNSPECIFIED {
+			statusArg := args.add(helper.TaskStatusDbEnumToString(change.status))
+			set += `, status = ` + statusArg + `, ` + completedAtUpdate(statusArg, nowArg)
+		}
+		if change.priority != pb.TaskPriority_TASK_PRIORITY_UNSPECIFIED {
+			set += `, priority = ` + args.add(helper.TaskPriorityDbEnumToString(change.priority))
+		}
+		if change.deadline != nil {
+			set += `, deadline = ` + args.add(change.deadline.AsTime())
+		} else if change.clearDeadline {
+			set += `, deadline = NULL`
+		}
+		if move {
+			position, err := ranks.appendRank(ctx, tx)
+			if err != nil {
+				log.Printf("Error ranking moved task %s: %v", taskID, err)
+				return nil, status.Error(codes.Internal, "failed to update tasks")
+			}
+			set += `, group_id = ` + args.add(change.groupID) + `, position = ` + args.add(position)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tasks SET `+set+` WHERE task_id = `+args.add(taskID), args...); err != nil {
+			log.Printf("Error applying batch update to task %s: %v", taskID, err)
+			return nil, status.Error(codes.Internal, "failed to update tasks")
+		}
+
+		statusChanged := change.status != pb.TaskStatus_TASK_STATUS_UNSPECIFIED && change.status != current.Status
+		if statusChanged || move {
+			touchedGroups = append(touchedGroups, current.GroupId)
+		}
+		if move {
+			touchedGroups = append(touchedGroups, change.groupID)
+		}
+		if statusChanged && change.status == pb.TaskStatus_TASK_STATUS_COMPLETED {
+			completed = append(completed, taskID)
+		}
+
+		result.Task, err = getTask(ctx, tx, taskID, false)
+		if err != nil {
+			log.Printf("Error fetching task %s after batch update: %v", taskID, err)
+			return nil, status.Error(codes.Internal, "failed to update tasks")
+		}
+	}
+
+	if err := recomputeGroupCounters(ctx, tx, now, touchedGroups...); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update tasks")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing batch task update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update tasks")
+	}
+
+	// Completing an occurrence of a recurring task schedules the next one.
+	for _, taskID := range completed {
+		if err := s.materializeNext(ctx, taskID); err != nil {
+			log.Printf("Error materializing next occurrence of task %s: %v", taskID, err)
+		}
+	}
+
+	return results, nil
+}
+
+// setBatchError records err, a gRPC status error, as the result of a skipped task.
+func setBatchError(result *pb.TaskBatchResult, err error) {
+	st := status.Convert(err)
+	result.Code = int32(st.Code())
+	result.Message = st.Message()
+}
@@ -0,0 +1,87 @@
+/*
+File: internal/task_management/credit.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Crediting completed pomodoro sessions to their task.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+)
+
+// CreditPomodoro records one completed focus session, and the time focused in it, against taskID.
+// The task_pomodoro_credits ledger is keyed by session, so crediting the same
+// session again is a no-op; callers may retry freely.
+func (s *Service) CreditPomodoro(ctx context.Context, sessionID, taskID string, focused time.Duration) error {
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO task_pomodoro_credits (session_id, task_id, credited_at, focused_seconds)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id) DO NOTHING`, sessionID, taskID, now, int64(focused/time.Second))
+	if err != nil {
+		return err
+	}
+	if inserted, _ := res.RowsAffected(); inserted == 0 {
+		return nil // already credited
+	}
+
+	// progress is a percentage of the pomodoro estimate; tasks without an estimate keep theirs.
+	var statusLabel, groupID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE tasks SET
+			completed_pomodoros = completed_pomodoros + 1,
+			progress = CASE
+				WHEN total_pomodoros > 0 THEN LEAST(100, (completed_pomodoros + 1) * 100 / total_pomodoros)
+				ELSE progress
+			END,
+			status = CASE
+				WHEN total_pomodoros > 0 AND completed_pomodoros + 1 >= total_pomodoros THEN $2
+				ELSE status
+			END,
+			completed_at = CASE
+				WHEN total_pomodoros > 0 AND completed_pomodoros + 1 >= total_pomodoros THEN COALESCE(completed_at, $3)
+				ELSE completed_at
+			END,
+			updated_at = $3
+		WHERE task_id = $1 AND deleted_at IS NULL
+		RETURNING status, group_id`,
+		taskID,
+		helper.TaskStatusDbEnumToString(pb.TaskStatus_TASK_STATUS_COMPLETED),
+		now,
+	).Scan(&statusLabel, &groupID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if groupID != "" {
+		if err := recomputeGroupCounters(ctx, tx, now, groupID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Finishing an occurrence of a recurring task schedules the next one.
+	if helper.TaskStatusDbStringToEnum(statusLabel) == pb.TaskStatus_TASK_STATUS_COMPLETED {
+		if err := s.materializeNext(ctx, taskID); err != nil {
+			log.Printf("Error materializing next occurrence of task %s: %v", taskID, err)
+		}
+	}
+	return nil
+}
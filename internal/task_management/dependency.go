@@ -0,0 +1,143 @@
+/*
+File: internal/task_management/dependency.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: "Depends on" relations between tasks and blocked-state checks.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddTaskDependency records that task_id cannot start before depends_on_id is completed.
+// Both tasks must belong to the same user, and the new edge must not close a cycle.
+func (s *Service) AddTaskDependency(ctx context.Context, req *pb.AddTaskDependencyRequest) (*pb.AddTaskDependencyResponse, error) {
+	if req.TaskId == "" || req.DependsOnId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id and depends_on_id are required")
+	}
+	if req.TaskId == req.DependsOnId {
+		return nil, status.Error(codes.InvalidArgument, "a task cannot depend on itself")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting dependency update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+	var depUserID string
+	err = tx.QueryRowContext(ctx, "SELECT user_id FROM tasks WHERE task_id = $1 AND deleted_at IS NULL", req.DependsOnId).Scan(&depUserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "dependency task not found")
+		}
+		log.Printf("Error fetching dependency task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+	if depUserID != task.UserId {
+		return nil, status.Error(codes.InvalidArgument, "tasks must belong to the same user")
+	}
+
+	// Serialize graph changes per user so two concurrent edges cannot close a cycle together.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "task_dependencies:"+task.UserId); err != nil {
+		log.Printf("Failed to lock dependencies of user %s: %v", task.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+
+	// The edge closes a cycle if task_id is already reachable from depends_on_id.
+	var cycle bool
+	err = tx.QueryRowContext(ctx, `
+		WITH RECURSIVE reachable(task_id) AS (
+			SELECT $1::uuid
+			UNION
+			SELECT d.depends_on_id
+			FROM task_dependencies d
+			JOIN reachable r ON d.task_id = r.task_id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE task_id = $2::uuid)`,
+		req.DependsOnId, req.TaskId).Scan(&cycle)
+	if err != nil {
+		log.Printf("Error checking dependency cycle: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+	if cycle {
+		return nil, status.Error(codes.FailedPrecondition, "dependency would create a cycle")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_dependencies (task_id, depends_on_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, req.TaskId, req.DependsOnId)
+	if err != nil {
+		log.Printf("Error adding task dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+
+	task, err = getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching task after adding dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to add task dependency")
+	}
+
+	return &pb.AddTaskDependencyResponse{Task: task}, nil
+}
+
+func (s *Service) RemoveTaskDependency(ctx context.Context, req *pb.RemoveTaskDependencyRequest) (*pb.RemoveTaskDependencyResponse, error) {
+	if req.TaskId == "" || req.DependsOnId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id and depends_on_id are required")
+	}
+
+	res, err := s.db.TaskDB.ExecContext(ctx,
+		"DELETE FROM task_dependencies WHERE task_id = $1 AND depends_on_id = $2",
+		req.TaskId, req.DependsOnId)
+	if err != nil {
+		log.Printf("Error removing task dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to remove task dependency")
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return nil, status.Error(codes.NotFound, "dependency not found")
+	}
+
+	task, err := getTask(ctx, s.db.TaskDB, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching task after removing dependency: %v", err)
+		return nil, status.Error(codes.Internal, "failed to remove task dependency")
+	}
+
+	return &pb.RemoveTaskDependencyResponse{Task: task}, nil
+}
+
+// checkStartAllowed rejects moving a blocked task to IN_PROGRESS unless force is set.
+// Tasks that are already in progress may be edited freely.
+func checkStartAllowed(current *pb.Task, next pb.TaskStatus, force bool) error {
+	if next != pb.TaskStatus_TASK_STATUS_IN_PROGRESS || current.Status == pb.TaskStatus_TASK_STATUS_IN_PROGRESS {
+		return nil
+	}
+	if current.Blocked && !force {
+		return status.Error(codes.FailedPrecondition, "task is blocked by unfinished dependencies; set force to start it anyway")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+File: internal/task_management/dependency_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the blocked-task start check.
+*/
+
+package task_management
+
+import (
+	"testing"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckStartAllowed(t *testing.T) {
+	blocked := &pb.Task{Status: pb.TaskStatus_TASK_STATUS_IDLE, Blocked: true}
+	running := &pb.Task{Status: pb.TaskStatus_TASK_STATUS_IN_PROGRESS, Blocked: true}
+	free := &pb.Task{Status: pb.TaskStatus_TASK_STATUS_IDLE}
+
+	cases := []struct {
+		name    string
+		current *pb.Task
+		next    pb.TaskStatus
+		force   bool
+		want    codes.Code
+	}{
+		{"blocked start", blocked, pb.TaskStatus_TASK_STATUS_IN_PROGRESS, false, codes.FailedPrecondition},
+		{"blocked forced start", blocked, pb.TaskStatus_TASK_STATUS_IN_PROGRESS, true, codes.OK},
+		{"blocked other status", blocked, pb.TaskStatus_TASK_STATUS_PENDING, false, codes.OK},
+		{"already running", running, pb.TaskStatus_TASK_STATUS_IN_PROGRESS, false, codes.OK},
+		{"unblocked start", free, pb.TaskStatus_TASK_STATUS_IN_PROGRESS, false, codes.OK},
+	}
+	for _, c := range cases {
+		if got := status.Code(checkStartAllowed(c.current, c.next, c.force)); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+/*
+File: internal/task_management/estimate.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Pomodoro estimate history and estimation accuracy against credited sessions.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// recordEstimateChange keeps a re-estimate of a task in its history. A task estimated
+// for the first time takes the new estimate as its original instead, and is not counted
+// as re-estimated.
+func recordEstimateChange(ctx context.Context, q querier, taskID string, previous, next int32, now time.Time) error {
+	if previous > 0 {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO task_estimate_changes (task_id, previous_pomodoros, new_pomodoros, changed_at)
+			VALUES ($1, $2, $3, $4)`, taskID, previous, next, now); err != nil {
+			return err
+		}
+	}
+	_, err := q.ExecContext(ctx,
+		`UPDATE tasks SET original_pomodoros = $1 WHERE task_id = $2 AND original_pomodoros = 0`,
+		next, taskID)
+	return err
+}
+
+func (s *Service) GetTaskEstimateHistory(ctx context.Context, req *pb.GetTaskEstimateHistoryRequest) (*pb.GetTaskEstimateHistoryResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	task, err := getTask(ctx, s.db.TaskDB, req.TaskId, false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for estimate history: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch estimate history")
+	}
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT previous_pomodoros, new_pomodoros, changed_at
+		FROM task_estimate_changes
+		WHERE task_id = $1
+		ORDER BY changed_at, change_id`, req.TaskId)
+	if err != nil {
+		log.Printf("Error fetching estimate history: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch estimate history")
+	}
+	defer rows.Close()
+
+	resp := &pb.GetTaskEstimateHistoryResponse{OriginalPomodoros: task.OriginalPomodoros}
+	for rows.Next() {
+		var (
+			change    pb.TaskEstimateChange
+			changedAt time.Time
+		)
+		if err := rows.Scan(&change.PreviousPomodoros, &change.NewPomodoros, &changedAt); err != nil {
+			log.Printf("Error scanning estimate change: %v", err)
+			return nil, status.Error(codes.Internal, "failed to fetch estimate history")
+		}
+		change.ChangedAt = timestamppb.New(changedAt)
+		resp.Changes = append(resp.Changes, &change)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch estimate history")
+	}
+
+	return resp, nil
+}
+
+// accuracySums are the per-bucket totals the accuracy queries select, in scan order.
+type accuracySums struct {
+	key, label           string
+	tasks                int32
+	estimated, actual    int32
+	focusedSeconds       int64
+	relativeErrors       float64 // sum of |actual - estimate| / estimate
+	under, over, changed int32
+}
+
+const accuracySumColumns = `COUNT(*),
+		COALESCE(SUM(p.estimate), 0),
+		COALESCE(SUM(p.actual), 0),
+		COALESCE(SUM(p.focused), 0),
+		COALESCE(SUM(ABS(p.actual - p.estimate)::float8 / p.estimate), 0),
+		COUNT(*) FILTER (WHERE p.actual > p.estimate),
+		COUNT(*) FILTER (WHERE p.actual < p.estimate),
+		COUNT(*) FILTER (WHERE p.reestimated)`
+
+func (a *accuracySums) scan(row rowScanner) error {
+	return row.Scan(&a.key, &a.label,
+		&a.tasks, &a.estimated, &a.actual, &a.focusedSeconds, &a.relativeErrors,
+		&a.under, &a.over, &a.changed)
+}
+
+func (a accuracySums) accuracy() *pb.EstimationAccuracy {
+	acc := &pb.EstimationAccuracy{
+		Key:                 a.key,
+		Label:               a.label,
+		TaskCount:           a.tasks,
+		EstimatedPomodoros:  a.estimated,
+		ActualPomodoros:     a.actual,
+		FocusedMinutes:      int32(a.focusedSeconds / 60),
+		UnderestimatedTasks: a.under,
+		OverestimatedTasks:  a.over,
+		ReestimatedTasks:    a.changed,
+	}
+	if a.estimated > 0 {
+		acc.ActualToEstimateRatio = float64(a.actual) / float64(a.estimated)
+	}
+	if a.tasks > 0 {
+		acc.MeanAbsolutePercentageError = a.relativeErrors / float64(a.tasks) * 100
+	}
+	return acc
+}
+
+// GetEstimationAccuracy compares the original estimate of a user's completed tasks with
+// the focus sessions credited to them, overall, per group and per tag. A task counts
+// towards every tag it carries.
+func (s *Service) GetEstimationAccuracy(ctx context.Context, req *pb.GetEstimationAccuracyRequest) (*pb.GetEstimationAccuracyResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Imported tasks carry their pomodoro count without the credits behind it, so the
+	// actual count is whichever is higher; focused time only comes from credits.
+	args := queryArgs{req.UserId}
+	perTask := `WITH p AS (
+			SELECT t.task_id, t.group_id, t.original_pomodoros AS estimate,
+				GREATEST(t.completed_pomodoros, (SELECT COUNT(*) FROM task_pomodoro_credits c WHERE c.task_id = t.task_id)) AS actual,
+				(SELECT COALESCE(SUM(c.focused_seconds), 0) FROM task_pomodoro_credits c WHERE c.task_id = t.task_id) AS focused,
+				EXISTS (SELECT 1 FROM task_estimate_changes e WHERE e.task_id = t.task_id AND e.previous_pomodoros > 0) AS reestimated
+			FROM tasks t
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.original_pomodoros > 0
+			  AND t.status = 'completed'`
+	if req.CompletedSince != nil {
+		perTask += ` AND t.completed_at >= ` + args.add(req.CompletedSince.AsTime())
+	}
+	perTask += `
+		) `
+
+	queries := []string{
+		perTask + `SELECT '', '', ` + accuracySumColumns + ` FROM p`,
+		perTask + `SELECT g.group_id::text, g.name, ` + accuracySumColumns + `
+			FROM p JOIN task_groups g ON g.group_id = p.group_id
+			GROUP BY g.group_id, g.name
+			ORDER BY g.name, g.group_id`,
+		perTask + `SELECT tg.tag_id::text, tg.name, ` + accuracySumColumns + `
+			FROM p
+			JOIN task_tags tt ON tt.task_id = p.task_id
+			JOIN tags tg ON tg.tag_id = tt.tag_id
+			GROUP BY tg.tag_id, tg.name
+			ORDER BY tg.name, tg.tag_id`,
+	}
+
+	var buckets [3][]*pb.EstimationAccuracy
+	for i, query := range queries {
+		rows, err := s.db.TaskDB.QueryContext(ctx, query, args...)
+		if err != nil {
+			log.Printf("Error computing estimation accuracy: %v", err)
+			return nil, status.Error(codes.Internal, "failed to compute estimation accuracy")
+		}
+		for rows.Next() {
+			var sums accuracySums
+			if err := sums.scan(rows); err != nil {
+				rows.Close()
+				log.Printf("Error scanning estimation accuracy: %v", err)
+				return nil, status.Error(codes.Internal, "failed to compute estimation accuracy")
+			}
+			buckets[i] = append(buckets[i], sums.accuracy())
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			log.Printf("Row iteration error: %v", err)
+			return nil, status.Error(codes.Internal, "failed to compute estimation accuracy")
+		}
+	}
+
+	resp := &pb.GetEstimationAccuracyResponse{ByGroup: buckets[1], ByTag: buckets[2]}
+	if len(buckets[0]) > 0 {
+		resp.Overall = buckets[0][0]
+	}
+	return resp, nil
+}
@@ -0,0 +1,46 @@
+/*
+File: internal/task_management/estimate_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for estimation accuracy figures.
+*/
+
+package task_management
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimationAccuracyFromSums(t *testing.T) {
+	// Two tasks: estimated 4, took 6 (+50%); estimated 2, took 1 (-50%).
+	acc := accuracySums{
+		key:            "g1",
+		label:          "Sprint",
+		tasks:          2,
+		estimated:      6,
+		actual:         7,
+		focusedSeconds: 7*25*60 + 59,
+		relativeErrors: 0.5 + 0.5,
+		under:          1,
+		over:           1,
+	}.accuracy()
+
+	if acc.Key != "g1" || acc.Label != "Sprint" || acc.TaskCount != 2 {
+		t.Errorf("Unexpected identity: %+v", acc)
+	}
+	if math.Abs(acc.ActualToEstimateRatio-7.0/6.0) > 1e-9 {
+		t.Errorf("Expected ratio 7/6, got %v", acc.ActualToEstimateRatio)
+	}
+	if math.Abs(acc.MeanAbsolutePercentageError-50) > 1e-9 {
+		t.Errorf("Expected 50%% mean error, got %v", acc.MeanAbsolutePercentageError)
+	}
+	if acc.FocusedMinutes != 175 {
+		t.Errorf("Expected 175 focused minutes, got %d", acc.FocusedMinutes)
+	}
+
+	// No completed tasks: everything stays zero rather than dividing by zero.
+	if empty := (accuracySums{}).accuracy(); empty.ActualToEstimateRatio != 0 || empty.MeanAbsolutePercentageError != 0 {
+		t.Errorf("Expected zero figures without tasks, got %+v", empty)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+File: internal/task_management/export.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Streaming export of a user's task groups and tasks.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportChunkSize is how much of an export is buffered before it is sent as one chunk.
+const exportChunkSize = 32 << 10
+
+// chunkSender sends what is written to it as export chunks. The first chunk, sent even
+// for an empty file, carries the file's metadata.
+type chunkSender struct {
+	stream      pb.TaskManagementService_ExportTasksServer
+	contentType string
+	filename    string
+	buf         []byte
+	sent        bool
+}
+
+func (c *chunkSender) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= exportChunkSize {
+		if err := c.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *chunkSender) flush() error {
+	if len(c.buf) == 0 && c.sent {
+		return nil
+	}
+	chunk := &pb.ExportChunk{Data: c.buf}
+	if !c.sent {
+		chunk.ContentType, chunk.Filename = c.contentType, c.filename
+	}
+	if err := c.stream.Send(chunk); err != nil {
+		return err
+	}
+	c.buf, c.sent = nil, true
+	return nil
+}
+
+// ExportTasks streams the user's live groups, archived ones included, and their tasks
+// as a file. Groups and tasks are read in one snapshot and written in the user's order.
+func (s *Service) ExportTasks(req *pb.ExportTasksRequest, stream pb.TaskManagementService_ExportTasksServer) error {
+	if req.UserId == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+	exportFormat := pb.ExportFormat_EXPORT_FORMAT_JSON
+	if req.Format != pb.ExportFormat_EXPORT_FORMAT_UNSPECIFIED {
+		exportFormat = req.Format
+	}
+	format, ok := exportFormats[exportFormat]
+	if !ok {
+		return status.Error(codes.InvalidArgument, "format must be JSON, CSV or ICS")
+	}
+
+	ctx := stream.Context()
+	tx, err := s.db.TaskDB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		log.Printf("Error starting task export: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+taskGroupColumns+` FROM task_groups
+		WHERE user_id = $1 AND deleted_at IS NULL`+groupRankList(req.UserId).orderBy(), req.UserId)
+	if err != nil {
+		log.Printf("Error fetching task groups for export: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	var groups []*pb.TaskGroup
+	groupsByID := make(map[string]*pb.TaskGroup)
+	for rows.Next() {
+		group, err := scanTaskGroup(rows)
+		if err != nil {
+			rows.Close()
+			log.Printf("Error scanning task group for export: %v", err)
+			return status.Error(codes.Internal, "failed to export tasks")
+		}
+		groups = append(groups, group)
+		groupsByID[group.GroupId] = group
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+
+	now := time.Now()
+	out := &chunkSender{
+		stream:      stream,
+		contentType: format.contentType,
+		filename:    "totodoro-tasks-" + now.UTC().Format("20060102") + format.extension,
+	}
+	enc := format.encoder(out, req.UserId, now)
+	if err := enc.begin(groups); err != nil {
+		return err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+taskColumns+` FROM tasks
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND group_id IN (SELECT group_id FROM task_groups WHERE user_id = $1 AND deleted_at IS NULL)
+		ORDER BY (SELECT g.position FROM task_groups g WHERE g.group_id = tasks.group_id),
+			group_id::text COLLATE "C", position, task_id::text COLLATE "C"`, req.UserId)
+	if err != nil {
+		log.Printf("Error fetching tasks for export: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	defer rows.Close()
+	exported := 0
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			log.Printf("Error scanning task for export: %v", err)
+			return status.Error(codes.Internal, "failed to export tasks")
+		}
+		// A send failure means the client went away; its error already carries a status.
+		if err := enc.task(task, groupsByID[task.GroupId]); err != nil {
+			return err
+		}
+		exported++
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	rows.Close()
+
+	if details, ok := enc.(detailEncoder); ok {
+		if err := exportDetails(ctx, tx, req.UserId, details); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.end(); err != nil {
+		return err
+	}
+	if err := out.flush(); err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d tasks in %d groups for user %s as %s", exported, len(groups), req.UserId, exportFormat)
+	return nil
+}
+
+// exportDetails writes the checklists of the exported tasks and the user's tags.
+func exportDetails(ctx context.Context, tx *sql.Tx, userID string, enc detailEncoder) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+subtaskColumns+` FROM subtasks
+		WHERE task_id IN (
+			SELECT task_id FROM tasks
+			WHERE user_id = $1 AND deleted_at IS NULL
+			  AND group_id IN (SELECT group_id FROM task_groups WHERE user_id = $1 AND deleted_at IS NULL)
+		)
+		ORDER BY task_id, position, created_at`, userID)
+	if err != nil {
+		log.Printf("Error fetching subtasks for export: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		subtask, err := scanSubtask(rows)
+		if err != nil {
+			log.Printf("Error scanning subtask for export: %v", err)
+			return status.Error(codes.Internal, "failed to export tasks")
+		}
+		if err := enc.subtask(subtask); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	rows.Close()
+
+	rows, err = tx.QueryContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE user_id = $1 ORDER BY LOWER(name)`, userID)
+	if err != nil {
+		log.Printf("Error fetching tags for export: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			log.Printf("Error scanning tag for export: %v", err)
+			return status.Error(codes.Internal, "failed to export tasks")
+		}
+		if err := enc.tag(tag); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return status.Error(codes.Internal, "failed to export tasks")
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+/*
+File: internal/task_management/export_formats.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Encoders writing exported groups and tasks as JSON, CSV and iCalendar VTODOs.
+*/
+
+package task_management
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportEncoder writes one export format. begin receives every group before the
+// tasks are written one by one, in order; group is nil for a task without one.
+type exportEncoder interface {
+	begin(groups []*pb.TaskGroup) error
+	task(task *pb.Task, group *pb.TaskGroup) error
+	end() error
+}
+
+// detailEncoder is implemented by formats that also carry checklists and tags.
+// ExportTasks writes them after the last task and before end.
+type detailEncoder interface {
+	subtask(subtask *pb.Subtask) error
+	tag(tag *pb.Tag) error
+}
+
+type exportFormat struct {
+	extension   string
+	contentType string
+	encoder     func(w io.Writer, userID string, now time.Time) exportEncoder
+}
+
+var exportFormats = map[pb.ExportFormat]exportFormat{
+	pb.ExportFormat_EXPORT_FORMAT_JSON: {".json", "application/json", newJSONExport},
+	pb.ExportFormat_EXPORT_FORMAT_CSV:  {".csv", "text/csv; charset=utf-8", newCSVExport},
+	pb.ExportFormat_EXPORT_FORMAT_ICS:  {".ics", "text/calendar; charset=utf-8", newICSExport},
+}
+
+// jsonExport writes a TaskExport piece by piece, so tasks never have to be held in memory.
+// Its repeated fields are written one array at a time; items counts those of the open one.
+type jsonExport struct {
+	w          io.Writer
+	userID     string
+	exportedAt time.Time
+	array      string
+	items      int
+}
+
+func newJSONExport(w io.Writer, userID string, now time.Time) exportEncoder {
+	return &jsonExport{w: w, userID: userID, exportedAt: now}
+}
+
+var exportMarshal = protojson.MarshalOptions{EmitUnpopulated: true}
+
+func (e *jsonExport) write(m proto.Message) error {
+	data, err := exportMarshal.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// item writes m to the named array, closing the previous array if it is another one.
+func (e *jsonExport) item(array string, m proto.Message) error {
+	if array != e.array {
+		if err := e.open(array); err != nil {
+			return err
+		}
+	}
+	if e.items > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.items++
+	return e.write(m)
+}
+
+// open closes the current array, if any, and starts the named one.
+func (e *jsonExport) open(array string) error {
+	sep := ","
+	if e.array != "" {
+		sep = "],"
+	}
+	e.array, e.items = array, 0
+	_, err := fmt.Fprintf(e.w, `%s"%s":[`, sep, array)
+	return err
+}
+
+func (e *jsonExport) begin(groups []*pb.TaskGroup) error {
+	userID, err := json.Marshal(e.userID)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(e.w, `{"userId":%s,"exportedAt":`, userID); err != nil {
+		return err
+	}
+	if err := e.write(timestamppb.New(e.exportedAt)); err != nil {
+		return err
+	}
+	if err := e.open("groups"); err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if err := e.item("groups", group); err != nil {
+			return err
+		}
+	}
+	return e.open("tasks")
+}
+
+func (e *jsonExport) task(task *pb.Task, _ *pb.TaskGroup) error {
+	return e.item("tasks", task)
+}
+
+func (e *jsonExport) subtask(subtask *pb.Subtask) error {
+	return e.item("subtasks", subtask)
+}
+
+func (e *jsonExport) tag(tag *pb.Tag) error {
+	return e.item("tags", tag)
+}
+
+func (e *jsonExport) end() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+// csvExportHeader starts with the columns CSV import reads by default.
+var csvExportHeader = []string{
+	"group", "name", "description", "priority", "status", "deadline", "pomodoros",
+	"completed_pomodoros", "progress", "task_id", "group_id", "created_at", "updated_at",
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func newCSVExport(w io.Writer, _ string, _ time.Time) exportEncoder {
+	return &csvExport{w: csv.NewWriter(w)}
+}
+
+func (e *csvExport) begin([]*pb.TaskGroup) error {
+	return e.w.Write(csvExportHeader)
+}
+
+func (e *csvExport) task(task *pb.Task, group *pb.TaskGroup) error {
+	var groupName, deadline string
+	if group != nil {
+		groupName = group.Name
+	}
+	if task.Deadline != nil {
+		deadline = task.Deadline.AsTime().UTC().Format(time.RFC3339)
+	}
+	return e.w.Write([]string{
+		groupName,
+		task.Name,
+		task.Description,
+		helper.TaskPriorityDbEnumToString(task.Priority),
+		helper.TaskStatusDbEnumToString(task.Status),
+		deadline,
+		strconv.Itoa(int(task.TotalPomodoros)),
+		strconv.Itoa(int(task.CompletedPomodoros)),
+		strconv.Itoa(int(task.Progress)),
+		task.TaskId,
+		task.GroupId,
+		task.CreatedAt.AsTime().UTC().Format(time.RFC3339),
+		task.UpdatedAt.AsTime().UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvExport) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// icsExport writes an RFC 5545 calendar with one VTODO per task. Groups become categories.
+type icsExport struct {
+	w     io.Writer
+	stamp string
+}
+
+func newICSExport(w io.Writer, _ string, now time.Time) exportEncoder {
+	return &icsExport{w: w, stamp: icsTime(now)}
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsEscape escapes a TEXT value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// icsFold splits a content line into lines of at most 75 octets, continued by a
+// leading space, without breaking a UTF-8 sequence. Every line ends in CRLF.
+func icsFold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		n := utf8.RuneLen(r)
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// icsPriority maps task priorities onto iCalendar's 1 (highest) to 9 (lowest).
+func icsPriority(p pb.TaskPriority) int {
+	switch p {
+	case pb.TaskPriority_TASK_PRIORITY_HIGH:
+		return 1
+	case pb.TaskPriority_TASK_PRIORITY_LOW:
+		return 9
+	default:
+		return 5
+	}
+}
+
+func icsStatus(s pb.TaskStatus) string {
+	switch s {
+	case pb.TaskStatus_TASK_STATUS_COMPLETED:
+		return "COMPLETED"
+	case pb.TaskStatus_TASK_STATUS_IN_PROGRESS:
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func (e *icsExport) lines(lines ...string) error {
+	for _, line := range lines {
+		if _, err := io.WriteString(e.w, icsFold(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *icsExport) begin([]*pb.TaskGroup) error {
+	return e.lines(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Totodoro//Task Export//EN",
+		"CALSCALE:GREGORIAN",
+	)
+}
+
+func (e *icsExport) task(task *pb.Task, group *pb.TaskGroup) error {
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + task.TaskId,
+		"DTSTAMP:" + e.stamp,
+		"CREATED:" + icsTime(task.CreatedAt.AsTime()),
+		"LAST-MODIFIED:" + icsTime(task.UpdatedAt.AsTime()),
+		"SUMMARY:" + icsEscape(task.Name),
+	}
+	if task.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsEscape(task.Description))
+	}
+	if group != nil {
+		lines = append(lines, "CATEGORIES:"+icsEscape(group.Name))
+	}
+	if task.Deadline != nil {
+		lines = append(lines, "DUE:"+icsTime(task.Deadline.AsTime()))
+	}
+	lines = append(lines,
+		"PRIORITY:"+strconv.Itoa(icsPriority(task.Priority)),
+		"STATUS:"+icsStatus(task.Status),
+		"PERCENT-COMPLETE:"+strconv.Itoa(int(task.Progress)),
+	)
+	if task.CompletedAt != nil {
+		lines = append(lines, "COMPLETED:"+icsTime(task.CompletedAt.AsTime()))
+	}
+	return e.lines(append(lines, "END:VTODO")...)
+}
+
+func (e *icsExport) end() error {
+	return e.lines("END:VCALENDAR")
+}
@@ -0,0 +1,184 @@
+/*
+File: internal/task_management/export_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the JSON, CSV and iCalendar export encoders.
+*/
+
+package task_management
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func exportFixture() ([]*pb.TaskGroup, []*pb.Task) {
+	created := timestamppb.New(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+	groups := []*pb.TaskGroup{
+		{GroupId: "g1", Name: "Work, misc", Priority: pb.TaskGroupPriority_TASK_GROUP_PRIORITY_HIGH, CreatedAt: created, UpdatedAt: created},
+		{GroupId: "g2", Name: "Empty", CreatedAt: created, UpdatedAt: created},
+	}
+	tasks := []*pb.Task{
+		{
+			TaskId: "t1", GroupId: "g1", Name: "Write report", Description: "Q3; draft\nthen review",
+			Priority: pb.TaskPriority_TASK_PRIORITY_HIGH, Status: pb.TaskStatus_TASK_STATUS_IN_PROGRESS,
+			TotalPomodoros: 4, CompletedPomodoros: 1, Progress: 25, OriginalPomodoros: 3,
+			Deadline: timestamppb.New(time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)), CreatedAt: created, UpdatedAt: created,
+			TagIds: []string{"tag1"}, RecurrenceRule: "FREQ=WEEKLY", SeriesId: "t1", Occurrence: 2,
+			DependsOn: []string{"t2"},
+		},
+		{
+			TaskId: "t2", GroupId: "g1", Name: "File expenses",
+			Priority: pb.TaskPriority_TASK_PRIORITY_LOW, Status: pb.TaskStatus_TASK_STATUS_COMPLETED,
+			Progress: 100, CreatedAt: created, CompletedAt: created,
+			UpdatedAt: timestamppb.New(time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC)),
+			DependsOn: []string{"t1"},
+		},
+	}
+	return groups, tasks
+}
+
+func exportDetailsFixture() ([]*pb.Subtask, []*pb.Tag) {
+	subtasks := []*pb.Subtask{
+		{SubtaskId: "s2", TaskId: "t1", Title: "Review", Position: 2},
+		{SubtaskId: "s1", TaskId: "t1", Title: "Draft", Done: true, Position: 1},
+	}
+	tags := []*pb.Tag{{TagId: "tag1", Name: "Deep work", Color: "#ff8800"}}
+	return subtasks, tags
+}
+
+func encodeExport(t *testing.T, format pb.ExportFormat) string {
+	t.Helper()
+	groups, tasks := exportFixture()
+	var buf bytes.Buffer
+	enc := exportFormats[format].encoder(&buf, "u1", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	if err := enc.begin(groups); err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	for _, task := range tasks {
+		if err := enc.task(task, groups[0]); err != nil {
+			t.Fatalf("task failed: %v", err)
+		}
+	}
+	if details, ok := enc.(detailEncoder); ok {
+		subtasks, tags := exportDetailsFixture()
+		for _, subtask := range subtasks {
+			if err := details.subtask(subtask); err != nil {
+				t.Fatalf("subtask failed: %v", err)
+			}
+		}
+		for _, tag := range tags {
+			if err := details.tag(tag); err != nil {
+				t.Fatalf("tag failed: %v", err)
+			}
+		}
+	}
+	if err := enc.end(); err != nil {
+		t.Fatalf("end failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	plan := &importPlan{defaultGroup: "Imported"}
+	if err := parseJSONImport(encodeExport(t, pb.ExportFormat_EXPORT_FORMAT_JSON), plan); err != nil {
+		t.Fatalf("parseJSONImport failed: %v", err)
+	}
+
+	// The dependency of t2 on t1 would close a cycle with t1's on t2, so it is dropped.
+	if len(plan.errors) != 1 || plan.errors[0].Row != 2 || len(plan.groups) != 2 {
+		t.Fatalf("Expected 2 groups and the cyclic dependency reported, got %+v %+v", plan.groups, plan.errors)
+	}
+	work, empty := plan.groups[0], plan.groups[1]
+	if work.name != "Work, misc" || work.priority != pb.TaskGroupPriority_TASK_GROUP_PRIORITY_HIGH || len(work.tasks) != 2 {
+		t.Errorf("Unexpected group: %+v", work)
+	}
+	if empty.name != "Empty" || !empty.keep || len(empty.tasks) != 0 {
+		t.Errorf("Expected the empty group to be kept, got %+v", empty)
+	}
+	report := work.tasks[0]
+	if report.description != "Q3; draft\nthen review" || report.status != pb.TaskStatus_TASK_STATUS_IN_PROGRESS ||
+		report.totalPomodoros != 4 || report.deadline == nil || report.deadline.Hour() != 17 {
+		t.Errorf("Unexpected task: %+v", report)
+	}
+	if report.completedPomodoros != 1 || report.originalPomodoros != 3 || report.progress != 25 {
+		t.Errorf("Expected pomodoro counts and progress kept, got %+v", report)
+	}
+	if len(report.subtasks) != 2 || report.subtasks[0] != (importSubtask{title: "Draft", done: true}) || report.subtasks[1].title != "Review" {
+		t.Errorf("Expected the checklist in order, got %+v", report.subtasks)
+	}
+	if len(report.tags) != 1 || report.tags[0] != "Deep work" || plan.tagColors["deep work"] != "#ff8800" {
+		t.Errorf("Expected the tag by name and color, got %v %v", report.tags, plan.tagColors)
+	}
+	if report.recurrenceRule != "FREQ=WEEKLY" || report.series != "t1" || report.occurrence != 2 {
+		t.Errorf("Expected the series kept, got %q %q %d", report.recurrenceRule, report.series, report.occurrence)
+	}
+	expenses := work.tasks[1]
+	if len(report.dependsOn) != 1 || report.dependsOn[0] != expenses || len(expenses.dependsOn) != 0 {
+		t.Errorf("Expected only the first dependency kept, got %v and %v", report.dependsOn, expenses.dependsOn)
+	}
+}
+
+func TestExportCSVReimports(t *testing.T) {
+	out := encodeExport(t, pb.ExportFormat_EXPORT_FORMAT_CSV)
+	if !strings.HasPrefix(out, "group,name,description,priority,status,deadline,pomodoros,") {
+		t.Fatalf("Unexpected header: %q", strings.SplitN(out, "\n", 2)[0])
+	}
+
+	plan := &importPlan{defaultGroup: "Imported"}
+	if err := parseCSVImport(out, nil, plan); err != nil {
+		t.Fatalf("parseCSVImport failed: %v", err)
+	}
+	if len(plan.errors) != 0 || len(plan.groups) != 1 || len(plan.groups[0].tasks) != 2 {
+		t.Fatalf("Expected both tasks back in one group, got %+v %+v", plan.groups, plan.errors)
+	}
+	report := plan.groups[0].tasks[0]
+	if plan.groups[0].name != "Work, misc" || report.priority != pb.TaskPriority_TASK_PRIORITY_HIGH ||
+		report.status != pb.TaskStatus_TASK_STATUS_IN_PROGRESS || report.totalPomodoros != 4 || report.deadline == nil {
+		t.Errorf("Unexpected task: %+v", report)
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	out := encodeExport(t, pb.ExportFormat_EXPORT_FORMAT_ICS)
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+	}
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n", "UID:t1\r\n", "DTSTAMP:20261016T120000Z\r\n",
+		`DESCRIPTION:Q3\; draft\nthen review` + "\r\n", `CATEGORIES:Work\, misc` + "\r\n",
+		"DUE:20261101T170000Z\r\n", "PRIORITY:1\r\n", "STATUS:IN-PROCESS\r\n", "PERCENT-COMPLETE:25\r\n",
+		"PRIORITY:9\r\n", "STATUS:COMPLETED\r\n", "COMPLETED:20261001T080000Z\r\n", "END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VTODO") != 2 || strings.Count(out, "COMPLETED:") != 1 {
+		t.Errorf("Unexpected VTODOs:\n%s", out)
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	folded := icsFold(line)
+
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n ")
+	if len(parts) != 2 || len(parts[0]) > 75 || len(parts[1])+1 > 75 {
+		t.Fatalf("Unexpected folding: %q", folded)
+	}
+	if strings.Join(parts, "") != line {
+		t.Errorf("Unfolding does not give the line back: %q", folded)
+	}
+	if got := icsFold("SUMMARY:short"); got != "SUMMARY:short\r\n" {
+		t.Errorf("Expected a short line to be left alone, got %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+File: internal/task_management/filter.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Optional SQL conditions and sort keys shared by the task listing and search queries.
+*/
+
+package task_management
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Each filter returns a condition starting with " AND ", or "" when the filter is unset.
+
+func statusFilter(args *queryArgs, statuses []pb.TaskStatus) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	labels := make([]string, len(statuses))
+	for i, st := range statuses {
+		labels[i] = helper.TaskStatusDbEnumToString(st)
+	}
+	return ` AND status = ANY(` + args.add(pq.Array(labels)) + `)`
+}
+
+func priorityFilter(args *queryArgs, priorities []pb.TaskPriority) string {
+	if len(priorities) == 0 {
+		return ""
+	}
+	labels := make([]string, len(priorities))
+	for i, p := range priorities {
+		labels[i] = helper.TaskPriorityDbEnumToString(p)
+	}
+	return ` AND priority = ANY(` + args.add(pq.Array(labels)) + `)`
+}
+
+// timeRangeFilter restricts column to [from, to). column must be a constant.
+func timeRangeFilter(args *queryArgs, column string, from, to *timestamppb.Timestamp) string {
+	cond := ""
+	if from != nil {
+		cond += ` AND ` + column + ` >= ` + args.add(from.AsTime())
+	}
+	if to != nil {
+		cond += ` AND ` + column + ` < ` + args.add(to.AsTime())
+	}
+	return cond
+}
+
+// archivedGroupFilter leaves out tasks whose group is archived unless include is set.
+func archivedGroupFilter(include bool) string {
+	if include {
+		return ""
+	}
+	return ` AND NOT EXISTS (
+		SELECT 1 FROM task_groups ag
+		WHERE ag.group_id = tasks.group_id AND ag.archived_at IS NOT NULL
+	)`
+}
+
+// sortKey is one column of a keyset ordering. Page tokens carry expr's text form,
+// which is cast back to typ for the comparison.
+type sortKey struct {
+	expr string
+	typ  string
+}
+
+// taskIDSortKey breaks ties in every ordering.
+var taskIDSortKey = sortKey{`task_id::text COLLATE "C"`, `text`}
+
+// taskSortKeys maps each sort option to its keys, most significant first; taskIDSortKey follows.
+var taskSortKeys = map[pb.TaskSortKey][]sortKey{
+	pb.TaskSortKey_TASK_SORT_KEY_POSITION: {
+		{`COALESCE((SELECT g.position FROM task_groups g WHERE g.group_id = tasks.group_id), '')`, `text`},
+		{`group_id::text COLLATE "C"`, `text`},
+		{`position`, `text`},
+	},
+	pb.TaskSortKey_TASK_SORT_KEY_DEADLINE: {
+		{`COALESCE(deadline, 'infinity')`, `timestamptz`},
+	},
+	pb.TaskSortKey_TASK_SORT_KEY_PRIORITY: {
+		{`CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 ELSE 0 END`, `int`},
+	},
+	pb.TaskSortKey_TASK_SORT_KEY_CREATED_AT: {
+		{`created_at`, `timestamptz`},
+	},
+}
+
+// taskOrdering returns the keys for sortBy, defaulting to the manual order.
+func taskOrdering(sortBy pb.TaskSortKey) []sortKey {
+	keys, ok := taskSortKeys[sortBy]
+	if !ok {
+		keys = taskSortKeys[pb.TaskSortKey_TASK_SORT_KEY_POSITION]
+	}
+	return append(slices.Clip(keys), taskIDSortKey)
+}
+
+// keyColumns selects the text form of each key, to be read back for the next page token.
+func keyColumns(keys []sortKey) string {
+	cols := make([]string, len(keys))
+	for i, k := range keys {
+		cols[i] = `(` + k.expr + `)::text`
+	}
+	return strings.Join(cols, ", ")
+}
+
+// keysetFilter returns the condition selecting rows after the page token values.
+func keysetFilter(args *queryArgs, keys []sortKey, values []string, desc bool) string {
+	exprs := make([]string, len(keys))
+	params := make([]string, len(keys))
+	for i, k := range keys {
+		exprs[i] = k.expr
+		params[i] = args.add(values[i]) + `::` + k.typ
+	}
+	cmp := ` > `
+	if desc {
+		cmp = ` < `
+	}
+	return ` AND (` + strings.Join(exprs, ", ") + `)` + cmp + `(` + strings.Join(params, ", ") + `)`
+}
+
+// orderBy returns the ORDER BY clause for keys.
+func orderBy(keys []sortKey, desc bool) string {
+	dir := ` ASC`
+	if desc {
+		dir = ` DESC`
+	}
+	exprs := make([]string, len(keys))
+	for i, k := range keys {
+		exprs[i] = k.expr + dir
+	}
+	return ` ORDER BY ` + strings.Join(exprs, ", ")
+}
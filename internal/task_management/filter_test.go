@@ -0,0 +1,46 @@
+/*
+File: internal/task_management/filter_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for keyset ordering helpers.
+*/
+
+package task_management
+
+import (
+	"testing"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+)
+
+func TestKeysetFilter(t *testing.T) {
+	keys := taskOrdering(pb.TaskSortKey_TASK_SORT_KEY_DEADLINE)
+	if len(keys) != 2 || keys[1] != taskIDSortKey {
+		t.Fatalf("Expected deadline then task_id, got %v", keys)
+	}
+
+	args := queryArgs{"user"}
+	got := keysetFilter(&args, keys, []string{"2025-09-01 09:00:00+00", "abc"}, true)
+	want := ` AND (COALESCE(deadline, 'infinity'), task_id::text COLLATE "C") < ($2::timestamptz, $3::text)`
+	if got != want {
+		t.Errorf("keysetFilter:\n got %s\nwant %s", got, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("Expected 3 args, got %d", len(args))
+	}
+
+	if got, want := orderBy(keys, false), ` ORDER BY COALESCE(deadline, 'infinity') ASC, task_id::text COLLATE "C" ASC`; got != want {
+		t.Errorf("orderBy:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestTaskOrderingDefaultsToPosition(t *testing.T) {
+	got := taskOrdering(pb.TaskSortKey_TASK_SORT_KEY_UNSPECIFIED)
+	if len(got) != len(taskSortKeys[pb.TaskSortKey_TASK_SORT_KEY_POSITION])+1 {
+		t.Errorf("Expected the position ordering, got %v", got)
+	}
+	// Appending the tie-breaker must not touch the shared table.
+	if n := len(taskSortKeys[pb.TaskSortKey_TASK_SORT_KEY_POSITION]); n != 3 {
+		t.Errorf("Expected 3 position keys in the table, got %d", n)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+File: internal/task_management/group_counters.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Task group counters and status derived from the group's tasks.
+*/
+
+package task_management
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// lockGroupTasks locks every task of a group, trashed or not, in task_id order. Writes
+// lock task rows before the group row (recomputeGroupCounters), so a group-wide change
+// must take its tasks' locks before it touches the group.
+func lockGroupTasks(ctx context.Context, q querier, groupID string) error {
+	_, err := q.ExecContext(ctx, `
+		SELECT 1 FROM tasks
+		WHERE group_id = $1
+		ORDER BY task_id
+		FOR UPDATE`, groupID)
+	return err
+}
+
+// recomputeGroupCounters recounts the tasks of each group, ignoring those in the trash,
+// and derives the group status: COMPLETED once every task is done, IN_PROGRESS once any
+// task is started or done, PENDING if any task is pending, IDLE otherwise (including empty groups).
+// Call it inside the transaction that changed the tasks.
+func recomputeGroupCounters(ctx context.Context, q querier, now time.Time, groupIDs ...string) error {
+	ids := slices.Compact(slices.Sorted(slices.Values(groupIDs)))
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Lock the groups first, in a stable order, so concurrent task changes recount
+	// one after another and each count sees the other's committed rows.
+	if _, err := q.ExecContext(ctx, `
+		SELECT 1 FROM task_groups
+		WHERE group_id::text = ANY($1)
+		ORDER BY group_id
+		FOR UPDATE`, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	_, err := q.ExecContext(ctx, `
+		UPDATE task_groups g SET
+			completed_tasks = c.completed,
+			total_tasks = c.total,
+			status = CASE
+				WHEN c.total > 0 AND c.completed = c.total THEN 'completed'
+				WHEN c.completed > 0 OR c.in_progress > 0 THEN 'in progress'
+				WHEN c.pending > 0 THEN 'pending'
+				ELSE 'idle'
+			END,
+			updated_at = $2
+		FROM (
+			SELECT g2.group_id,
+				COUNT(t.task_id) FILTER (WHERE t.status = 'completed') AS completed,
+				COUNT(t.task_id) FILTER (WHERE t.status = 'in progress') AS in_progress,
+				COUNT(t.task_id) FILTER (WHERE t.status = 'pending') AS pending,
+				COUNT(t.task_id) AS total
+			FROM task_groups g2
+			LEFT JOIN tasks t ON t.group_id = g2.group_id AND t.deleted_at IS NULL
+			WHERE g2.group_id::text = ANY($1)
+			GROUP BY g2.group_id
+		) c
+		WHERE g.group_id = c.group_id`,
+		pq.Array(ids), now)
+	return err
+}
@@ -0,0 +1,361 @@
+/*
+File: internal/task_management/import.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Importing task groups and tasks from CSV, Todoist backups, Markdown checklists and JSON exports.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxImportSize caps the size of an imported file, in bytes.
+	maxImportSize = 1 << 20
+	// maxImportTasks caps the number of tasks one import can create.
+	maxImportTasks = 1000
+	// defaultImportGroup holds imported tasks that name no group.
+	defaultImportGroup = "Imported"
+)
+
+// ImportTasks parses a file into groups and tasks and creates them in one transaction.
+// Tasks join the user's live group of the same name when there is one. Rows that fail
+// validation are reported and skipped; a dry run reports everything and saves nothing.
+func (s *Service) ImportTasks(ctx context.Context, req *pb.ImportTasksRequest) (*pb.ImportTasksResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+	if req.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	if len(req.Content) > maxImportSize {
+		return nil, status.Errorf(codes.InvalidArgument, "content must be at most %d bytes", maxImportSize)
+	}
+
+	plan := &importPlan{defaultGroup: defaultImportGroup}
+	if req.DefaultGroupName != "" {
+		plan.defaultGroup = req.DefaultGroupName
+	}
+	var err error
+	switch req.Format {
+	case pb.ImportFormat_IMPORT_FORMAT_CSV:
+		err = parseCSVImport(req.Content, req.CsvMapping, plan)
+	case pb.ImportFormat_IMPORT_FORMAT_TODOIST:
+		err = parseTodoistImport(req.Content, plan)
+	case pb.ImportFormat_IMPORT_FORMAT_MARKDOWN:
+		parseMarkdownImport(req.Content, plan)
+	case pb.ImportFormat_IMPORT_FORMAT_JSON:
+		err = parseJSONImport(req.Content, plan)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "format is required")
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if n := plan.taskCount(); n > maxImportTasks {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d tasks can be imported at once, the file has %d", maxImportTasks, n)
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task import: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import tasks")
+	}
+	defer tx.Rollback()
+
+	groupRanks := groupRankList(req.UserId)
+	if err := groupRanks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task group order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import tasks")
+	}
+
+	now := time.Now()
+	resp := &pb.ImportTasksResponse{Errors: plan.errors, DryRun: req.DryRun}
+	var (
+		groupIDs []string
+		taskIDs  [][]string
+	)
+	for _, group := range plan.groups {
+		if len(group.tasks) == 0 && !group.keep {
+			continue // A heading without tasks does not create a group.
+		}
+		groupID, groupTaskIDs, existing, err := importGroupTasks(ctx, tx, req.UserId, group, groupRanks, now)
+		if err != nil {
+			log.Printf("Error importing task group %q: %v", group.name, err)
+			return nil, status.Error(codes.Internal, "failed to import tasks")
+		}
+		groupIDs = append(groupIDs, groupID)
+		taskIDs = append(taskIDs, groupTaskIDs)
+		resp.Groups = append(resp.Groups, &pb.ImportedGroup{Existing: existing})
+		resp.ImportedTasks += int32(len(group.tasks))
+	}
+	if err := linkImportedTasks(ctx, tx, req.UserId, plan, now); err != nil {
+		log.Printf("Error linking imported tasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import tasks")
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, groupIDs...); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import tasks")
+	}
+
+	for i, groupID := range groupIDs {
+		if err := readImportedGroup(ctx, tx, groupID, taskIDs[i], resp.Groups[i]); err != nil {
+			log.Printf("Error fetching imported task group: %v", err)
+			return nil, status.Error(codes.Internal, "failed to import tasks")
+		}
+	}
+
+	if req.DryRun {
+		return resp, nil
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task import: %v", err)
+		return nil, status.Error(codes.Internal, "failed to import tasks")
+	}
+
+	log.Printf("Imported %d tasks in %d groups for user %s (%d rows skipped)",
+		resp.ImportedTasks, len(resp.Groups), req.UserId, len(resp.Errors))
+	return resp, nil
+}
+
+// importGroupTasks appends the group's tasks to the user's live, unarchived group of
+// the same name, creating the group at the bottom of the user's groups if there is none.
+func importGroupTasks(ctx context.Context, tx *sql.Tx, userID string, group *importGroup, groupRanks rankList, now time.Time) (string, []string, bool, error) {
+	var groupID string
+	err := tx.QueryRowContext(ctx, `
+		SELECT group_id FROM task_groups
+		WHERE user_id = $1 AND name = $2 AND deleted_at IS NULL AND archived_at IS NULL
+		ORDER BY position LIMIT 1`, userID, group.name).Scan(&groupID)
+	existing := err == nil
+	if err == sql.ErrNoRows {
+		position, err := groupRanks.appendRank(ctx, tx)
+		if err != nil {
+			return "", nil, false, err
+		}
+		groupID = uuid.NewString()
+		var deadline any
+		if group.deadline != nil {
+			deadline = *group.deadline
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO task_groups (
+				group_id, user_id, icon, name, description, deadline,
+				priority, status, completed_tasks, total_tasks,
+				created_at, updated_at, position
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,0,0,$9,$9,$10)`,
+			groupID,
+			userID,
+			group.icon,
+			group.name,
+			group.description,
+			deadline,
+			helper.TaskGroupPriorityDbEnumToString(group.priority),
+			helper.TaskGroupStatusDbEnumToString(pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE),
+			now,
+			position,
+		)
+		if err != nil {
+			return "", nil, false, err
+		}
+	} else if err != nil {
+		return "", nil, false, err
+	}
+
+	taskRanks := taskRankList(groupID)
+	if err := taskRanks.lock(ctx, tx); err != nil {
+		return "", nil, false, err
+	}
+	taskIDs := make([]string, 0, len(group.tasks))
+	for _, task := range group.tasks {
+		position, err := taskRanks.appendRank(ctx, tx)
+		if err != nil {
+			return "", nil, false, err
+		}
+		var deadline any
+		if task.deadline != nil {
+			deadline = *task.deadline
+		}
+		progress := task.progress
+		if progress == 0 && task.status == pb.TaskStatus_TASK_STATUS_COMPLETED {
+			progress = 100
+		}
+		original := task.originalPomodoros
+		if original == 0 {
+			original = task.totalPomodoros
+		}
+
+		taskID := uuid.NewString()
+		task.id = taskID
+		taskIDs = append(taskIDs, taskID)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO tasks (
+				task_id, user_id, group_id, icon, name, description,
+				priority, status, total_pomodoros, completed_pomodoros, progress,
+				deadline, created_at, updated_at, position, original_pomodoros, completed_at
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$13,$14,$15,$16)`,
+			taskID,
+			userID,
+			groupID,
+			task.icon,
+			task.name,
+			task.description,
+			helper.TaskPriorityDbEnumToString(task.priority),
+			helper.TaskStatusDbEnumToString(task.status),
+			task.totalPomodoros,
+			task.completedPomodoros,
+			progress,
+			deadline,
+			now,
+			position,
+			original,
+			completedAtValue(task.status, now),
+		)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if len(task.subtasks) == 0 {
+			continue
+		}
+
+		titles := make([]string, len(task.subtasks))
+		done := make([]bool, len(task.subtasks))
+		for i, subtask := range task.subtasks {
+			titles[i], done[i] = subtask.title, subtask.done
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subtasks (subtask_id, task_id, title, done, position, created_at, updated_at)
+			SELECT gen_random_uuid(), $1, s.title, s.done, s.position, $4, $4
+			FROM unnest($2::text[], $3::bool[]) WITH ORDINALITY AS s(title, done, position)`,
+			taskID, pq.Array(titles), pq.Array(done), now); err != nil {
+			return "", nil, false, err
+		}
+		if err := recomputeChecklistProgress(ctx, tx, taskID, now); err != nil {
+			return "", nil, false, err
+		}
+	}
+	return groupID, taskIDs, existing, nil
+}
+
+// linkImportedTasks restores what ties the created tasks to others: their tags, matched
+// by name or created, their dependencies and the recurring series they belong to.
+// An imported series is headed by its earliest occurrence in the file.
+func linkImportedTasks(ctx context.Context, tx *sql.Tx, userID string, plan *importPlan, now time.Time) error {
+	var (
+		tagIDs      = make(map[string]string)
+		series      = make(map[string][]*importTask)
+		seriesOrder []string
+	)
+	for _, group := range plan.groups {
+		for _, task := range group.tasks {
+			for _, name := range task.tags {
+				key := strings.ToLower(name)
+				tagID, ok := tagIDs[key]
+				if !ok {
+					err := tx.QueryRowContext(ctx, `
+						INSERT INTO tags (tag_id, user_id, name, color, created_at, updated_at)
+						VALUES ($1, $2, $3, $4, $5, $5)
+						ON CONFLICT (user_id, LOWER(name)) DO UPDATE SET name = tags.name
+						RETURNING tag_id`,
+						uuid.NewString(), userID, name, plan.tagColors[key], now,
+					).Scan(&tagID)
+					if err != nil {
+						return err
+					}
+					tagIDs[key] = tagID
+				}
+				if _, err := tx.ExecContext(ctx,
+					`INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+					task.id, tagID); err != nil {
+					return err
+				}
+			}
+			for _, dep := range task.dependsOn {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT INTO task_dependencies (task_id, depends_on_id, created_at) VALUES ($1, $2, $3)`,
+					task.id, dep.id, now); err != nil {
+					return err
+				}
+			}
+			if task.series != "" {
+				if _, ok := series[task.series]; !ok {
+					seriesOrder = append(seriesOrder, task.series)
+				}
+				series[task.series] = append(series[task.series], task)
+			}
+		}
+	}
+
+	for _, key := range seriesOrder {
+		occurrences := series[key]
+		head, last := occurrences[0], occurrences[0]
+		for _, task := range occurrences {
+			if task.occurrence < head.occurrence {
+				head = task
+			}
+			if task.occurrence > last.occurrence {
+				last = task
+			}
+		}
+		var start any
+		if head.deadline != nil {
+			start = *head.deadline
+		}
+		for _, task := range occurrences {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE tasks SET recurrence_rule = $1, recurrence_start = $2, series_id = $3, occurrence = $4
+				WHERE task_id = $5`,
+				task.recurrenceRule, start, head.id, task.occurrence, task.id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO task_series (series_id, last_occurrence) VALUES ($1, $2)`,
+			head.id, last.occurrence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readImportedGroup fills in an imported group and the tasks the import added to it.
+func readImportedGroup(ctx context.Context, tx *sql.Tx, groupID string, taskIDs []string, imported *pb.ImportedGroup) error {
+	var err error
+	imported.Group, err = scanTaskGroup(tx.QueryRowContext(ctx,
+		`SELECT `+taskGroupColumns+` FROM task_groups WHERE group_id = $1`, groupID))
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE task_id = ANY($1) ORDER BY position`,
+		pq.Array(taskIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return err
+		}
+		imported.Tasks = append(imported.Tasks, task)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,658 @@
+/*
+File: internal/task_management/import_formats.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Parsers turning CSV, Todoist JSON and Markdown checklists into an import plan.
+*/
+
+package task_management
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// importTask is a task read from an import file. The fields after subtasks are only
+// carried by JSON exports; id is set once the task is created.
+type importTask struct {
+	row            int32
+	icon           string
+	name           string
+	description    string
+	priority       pb.TaskPriority
+	status         pb.TaskStatus
+	deadline       *time.Time
+	totalPomodoros int32
+	subtasks       []importSubtask
+
+	completedPomodoros int32
+	originalPomodoros  int32 // 0 takes totalPomodoros
+	progress           int32 // 0 takes 100 for completed tasks
+	tags               []string
+	dependsOn          []*importTask
+	recurrenceRule     string
+	series             string // shared by the occurrences of one series in the file
+	occurrence         int32
+	id                 string
+}
+
+type importSubtask struct {
+	title string
+	done  bool
+}
+
+// importGroup is a group read from an import file. The metadata fields only apply when
+// the import creates the group; keep creates it even without tasks.
+type importGroup struct {
+	name        string
+	icon        string
+	description string
+	priority    pb.TaskGroupPriority
+	deadline    *time.Time
+	keep        bool
+	tasks       []*importTask
+}
+
+// importPlan is what an import file contains: groups in order of first appearance,
+// and the rows that were skipped.
+type importPlan struct {
+	defaultGroup string
+	groups       []*importGroup
+	errors       []*pb.ImportRowError
+	tagColors    map[string]string // By lower-cased tag name, for tags the import creates
+}
+
+// add puts task in the named group, or in the default group when name is empty.
+func (p *importPlan) add(groupName string, task *importTask) {
+	g := p.group(groupName)
+	g.tasks = append(g.tasks, task)
+}
+
+// group returns the named group, or the default group when name is empty, adding it if needed.
+func (p *importPlan) group(name string) *importGroup {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = p.defaultGroup
+	}
+	for _, g := range p.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	g := &importGroup{name: name}
+	p.groups = append(p.groups, g)
+	return g
+}
+
+func (p *importPlan) fail(row int, format string, args ...any) {
+	p.errors = append(p.errors, &pb.ImportRowError{Row: int32(row), Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *importPlan) taskCount() int {
+	n := 0
+	for _, g := range p.groups {
+		n += len(g.tasks)
+	}
+	return n
+}
+
+// parseImportDeadline accepts RFC 3339, a local date-time without zone, or a date.
+// Times without a zone are taken as UTC.
+func parseImportDeadline(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("deadline %q is not a date (use RFC 3339 or YYYY-MM-DD)", s)
+}
+
+func parseImportPriority(s string) (pb.TaskPriority, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return pb.TaskPriority_TASK_PRIORITY_UNSPECIFIED, nil
+	case "low":
+		return pb.TaskPriority_TASK_PRIORITY_LOW, nil
+	case "medium":
+		return pb.TaskPriority_TASK_PRIORITY_MEDIUM, nil
+	case "high":
+		return pb.TaskPriority_TASK_PRIORITY_HIGH, nil
+	}
+	return 0, fmt.Errorf("priority %q must be low, medium or high", s)
+}
+
+func parseImportStatus(s string) (pb.TaskStatus, error) {
+	switch strings.ToLower(s) {
+	case "", "idle", "todo":
+		return pb.TaskStatus_TASK_STATUS_IDLE, nil
+	case "pending":
+		return pb.TaskStatus_TASK_STATUS_PENDING, nil
+	case "in progress", "in_progress":
+		return pb.TaskStatus_TASK_STATUS_IN_PROGRESS, nil
+	case "completed", "done":
+		return pb.TaskStatus_TASK_STATUS_COMPLETED, nil
+	}
+	return 0, fmt.Errorf("status %q must be idle, pending, in progress or completed", s)
+}
+
+// csvColumns resolves the mapping against the header row. Fields mapped explicitly must
+// exist; fields left to their default name are optional, except name.
+func csvColumns(header []string, m *pb.CsvColumnMapping) (map[string]int, error) {
+	if m == nil {
+		m = &pb.CsvColumnMapping{}
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	fields := []struct{ field, mapped, def string }{
+		{"group", m.Group, "group"},
+		{"name", m.Name, "name"},
+		{"description", m.Description, "description"},
+		{"priority", m.Priority, "priority"},
+		{"status", m.Status, "status"},
+		{"deadline", m.Deadline, "deadline"},
+		{"total_pomodoros", m.TotalPomodoros, "pomodoros"},
+	}
+	cols := make(map[string]int)
+	for _, f := range fields {
+		name := f.mapped
+		if name == "" {
+			name = f.def
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		switch {
+		case ok:
+			cols[f.field] = i
+		case f.mapped != "" || f.field == "name":
+			return nil, fmt.Errorf("column %q not found in the header row", name)
+		}
+	}
+	return cols, nil
+}
+
+// parseCSVImport reads a header row and one task per row. It returns an error only
+// when the file as a whole cannot be read; bad rows are recorded in the plan.
+func parseCSVImport(content string, mapping *pb.CsvColumnMapping, plan *importPlan) error {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\uFEFF")))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return errors.New("the CSV file is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read the CSV header: %v", err)
+	}
+	cols, err := csvColumns(header, mapping)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			plan.fail(parseErr.StartLine, "%v", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read the CSV file: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue // blank line
+		}
+
+		task := &importTask{row: int32(line), name: field("name"), description: field("description")}
+		if task.name == "" {
+			plan.fail(line, "name is required")
+			continue
+		}
+		if task.priority, err = parseImportPriority(field("priority")); err != nil {
+			plan.fail(line, "%v", err)
+			continue
+		}
+		if task.status, err = parseImportStatus(field("status")); err != nil {
+			plan.fail(line, "%v", err)
+			continue
+		}
+		if d := field("deadline"); d != "" {
+			if task.deadline, err = parseImportDeadline(d); err != nil {
+				plan.fail(line, "%v", err)
+				continue
+			}
+		}
+		if p := field("total_pomodoros"); p != "" {
+			n, err := strconv.ParseInt(p, 10, 32)
+			if err != nil || n < 0 {
+				plan.fail(line, "pomodoros %q must be a whole number, 0 or more", p)
+				continue
+			}
+			task.totalPomodoros = int32(n)
+		}
+		plan.add(field("group"), task)
+	}
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	markdownCheckbox = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\](?:\s+(.*))?$`)
+	markdownBadBox   = regexp.MustCompile(`^\s*[-*+]\s+\[[^\]]*\]`)
+)
+
+// markdownIndent measures leading whitespace, counting a tab as four spaces.
+func markdownIndent(s string) int {
+	n := 0
+	for _, r := range s {
+		if r == '\t' {
+			n += 4
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// parseMarkdownImport reads "- [ ]" / "- [x]" checklist items. Headings start a new group,
+// items nested under an item become its subtasks, and other lines are ignored.
+func parseMarkdownImport(content string, plan *importPlan) {
+	var (
+		groupName  string
+		current    *importTask
+		taskIndent int
+	)
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line = strings.TrimRight(line, "\r")
+
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			groupName, current = m[1], nil
+			continue
+		}
+		m := markdownCheckbox.FindStringSubmatch(line)
+		if m == nil {
+			if markdownBadBox.MatchString(line) {
+				plan.fail(lineNo, "checkbox must be [ ] or [x]")
+			}
+			continue
+		}
+
+		indent, done, text := markdownIndent(m[1]), m[2] != " ", strings.TrimSpace(m[3])
+		if text == "" {
+			plan.fail(lineNo, "checklist item has no text")
+			continue
+		}
+		if current != nil && indent > taskIndent {
+			if len(text) > maxSubtaskTitle {
+				plan.fail(lineNo, "subtask must be at most %d characters", maxSubtaskTitle)
+				continue
+			}
+			current.subtasks = append(current.subtasks, importSubtask{title: text, done: done})
+			continue
+		}
+
+		current, taskIndent = &importTask{row: int32(lineNo), name: text, status: pb.TaskStatus_TASK_STATUS_IDLE}, indent
+		if done {
+			current.status = pb.TaskStatus_TASK_STATUS_COMPLETED
+		}
+		plan.add(groupName, current)
+	}
+}
+
+// todoistID accepts both the string and the older numeric IDs of Todoist backups.
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*id = todoistID(n.String())
+	return nil
+}
+
+// todoistFlag accepts both true/false and the older 1/0 flags.
+type todoistFlag bool
+
+func (f *todoistFlag) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "true", "1":
+		*f = true
+	case "false", "0", "null":
+		*f = false
+	default:
+		return fmt.Errorf("invalid flag %s", b)
+	}
+	return nil
+}
+
+type todoistBackup struct {
+	Projects []struct {
+		ID   todoistID `json:"id"`
+		Name string    `json:"name"`
+	} `json:"projects"`
+	Items []struct {
+		ID          todoistID   `json:"id"`
+		ProjectID   todoistID   `json:"project_id"`
+		ParentID    todoistID   `json:"parent_id"`
+		Content     string      `json:"content"`
+		Description string      `json:"description"`
+		Priority    int         `json:"priority"`
+		Checked     todoistFlag `json:"checked"`
+		ChildOrder  int         `json:"child_order"`
+		Due         *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+}
+
+// todoistPriority maps Todoist's 1 (normal) to 4 (urgent) onto task priorities.
+func todoistPriority(p int) pb.TaskPriority {
+	switch {
+	case p >= 4:
+		return pb.TaskPriority_TASK_PRIORITY_HIGH
+	case p == 3:
+		return pb.TaskPriority_TASK_PRIORITY_MEDIUM
+	default:
+		return pb.TaskPriority_TASK_PRIORITY_LOW
+	}
+}
+
+// parseTodoistImport reads a Todoist JSON backup. Projects become groups and top-level
+// items tasks; sub-items at any depth become subtasks of their top-level item.
+// Rows are 1-based indexes into items.
+func parseTodoistImport(content string, plan *importPlan) error {
+	var backup todoistBackup
+	if err := json.Unmarshal([]byte(content), &backup); err != nil {
+		return fmt.Errorf("cannot read the Todoist backup: %v", err)
+	}
+
+	projects := make(map[todoistID]string, len(backup.Projects))
+	for _, p := range backup.Projects {
+		projects[p.ID] = p.Name
+	}
+
+	// Items keep their Todoist order within each parent.
+	order := make([]int, len(backup.Items))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return backup.Items[a].ChildOrder - backup.Items[b].ChildOrder
+	})
+
+	parents := make(map[todoistID]todoistID, len(backup.Items))
+	for _, item := range backup.Items {
+		parents[item.ID] = item.ParentID
+	}
+	tasks := make(map[todoistID]*importTask)
+
+	for _, i := range order {
+		item := backup.Items[i]
+		if item.ParentID != "" {
+			continue
+		}
+		row := i + 1
+		name := strings.TrimSpace(item.Content)
+		if name == "" {
+			plan.fail(row, "item has no content")
+			continue
+		}
+		groupName, ok := projects[item.ProjectID]
+		if !ok && item.ProjectID != "" {
+			plan.fail(row, "item belongs to unknown project %s", item.ProjectID)
+			continue
+		}
+
+		task := &importTask{
+			row:         int32(row),
+			name:        name,
+			description: strings.TrimSpace(item.Description),
+			priority:    todoistPriority(item.Priority),
+			status:      pb.TaskStatus_TASK_STATUS_IDLE,
+		}
+		if item.Checked {
+			task.status = pb.TaskStatus_TASK_STATUS_COMPLETED
+		}
+		if item.Due != nil && item.Due.Date != "" {
+			deadline, err := parseImportDeadline(item.Due.Date)
+			if err != nil {
+				plan.fail(row, "%v", err)
+				continue
+			}
+			task.deadline = deadline
+		}
+		tasks[item.ID] = task
+		plan.add(groupName, task)
+	}
+
+	for _, i := range order {
+		item := backup.Items[i]
+		if item.ParentID == "" {
+			continue
+		}
+		row := i + 1
+		// Walk up to the top-level item; the bound stops on cyclic parents.
+		root := item.ParentID
+		for depth := 0; parents[root] != "" && depth < len(backup.Items); depth++ {
+			root = parents[root]
+		}
+		task, ok := tasks[root]
+		if !ok {
+			plan.fail(row, "parent item %s was not imported", item.ParentID)
+			continue
+		}
+		title := strings.TrimSpace(item.Content)
+		if title == "" || len(title) > maxSubtaskTitle {
+			plan.fail(row, "sub-item must have between 1 and %d characters", maxSubtaskTitle)
+			continue
+		}
+		task.subtasks = append(task.subtasks, importSubtask{title: title, done: bool(item.Checked)})
+	}
+	return nil
+}
+
+// parseJSONImport reads a TaskExport. Every group is recreated, with or without tasks;
+// rows are 1-based indexes into tasks. Dependencies on tasks that are not imported are
+// dropped, as are those that would close a cycle.
+func parseJSONImport(content string, plan *importPlan) error {
+	var export pb.TaskExport
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(content), &export); err != nil {
+		return fmt.Errorf("cannot read the JSON export: %v", err)
+	}
+
+	groups := make(map[string]*importGroup, len(export.Groups))
+	for _, g := range export.Groups {
+		group := plan.group(g.Name)
+		group.icon, group.description, group.priority, group.keep = g.Icon, g.Description, g.Priority, true
+		if g.Deadline != nil {
+			deadline := g.Deadline.AsTime()
+			group.deadline = &deadline
+		}
+		groups[g.GroupId] = group
+	}
+
+	tags := make(map[string]string, len(export.Tags))
+	plan.tagColors = make(map[string]string, len(export.Tags))
+	for _, tag := range export.Tags {
+		name, err := validateTag(tag.Name, tag.Color)
+		if err != nil {
+			continue // Tasks carrying it are reported below.
+		}
+		tags[tag.TagId] = name
+		plan.tagColors[strings.ToLower(name)] = tag.Color
+	}
+	subtasks := make(map[string][]*pb.Subtask)
+	for _, st := range export.Subtasks {
+		subtasks[st.TaskId] = append(subtasks[st.TaskId], st)
+	}
+
+	var (
+		imported    = make(map[string]*importTask, len(export.Tasks))
+		occurrences = make(map[string]bool)
+	)
+	for i, t := range export.Tasks {
+		row := i + 1
+		group, ok := groups[t.GroupId]
+		if !ok && t.GroupId != "" {
+			plan.fail(row, "task belongs to unknown group %s", t.GroupId)
+			continue
+		}
+		task, err := importTaskFromExport(t, tags, subtasks[t.TaskId])
+		if err != nil {
+			plan.fail(row, "%v", err)
+			continue
+		}
+		task.row = int32(row)
+		if task.series == "" && task.recurrenceRule != "" {
+			task.series, task.occurrence = "row:"+strconv.Itoa(row), 1
+		}
+		if task.series != "" {
+			key := task.series + "/" + strconv.Itoa(int(task.occurrence))
+			if occurrences[key] {
+				plan.fail(row, "occurrence %d of series %s appears twice", task.occurrence, task.series)
+				continue
+			}
+			occurrences[key] = true
+		}
+
+		if group == nil {
+			group = plan.group("")
+		}
+		group.tasks = append(group.tasks, task)
+		if t.TaskId != "" {
+			imported[t.TaskId] = task
+		}
+	}
+
+	for i, t := range export.Tasks {
+		task, ok := imported[t.TaskId]
+		if !ok || task.row != int32(i+1) {
+			continue
+		}
+		for _, id := range t.DependsOn {
+			dep, ok := imported[id]
+			if !ok {
+				continue
+			}
+			if dep == task || dependsOn(dep, task) {
+				plan.fail(i+1, "dependency on task %s skipped: it would create a cycle", id)
+				continue
+			}
+			task.dependsOn = append(task.dependsOn, dep)
+		}
+	}
+	return nil
+}
+
+// importTaskFromExport validates one exported task, with its tag names looked up in tags
+// (by tag ID) and its checklist in subtasks.
+func importTaskFromExport(t *pb.Task, tags map[string]string, subtasks []*pb.Subtask) (*importTask, error) {
+	name := strings.TrimSpace(t.Name)
+	switch {
+	case name == "":
+		return nil, errors.New("task has no name")
+	case t.TotalPomodoros < 0 || t.CompletedPomodoros < 0 || t.OriginalPomodoros < 0:
+		return nil, errors.New("pomodoro counts must not be negative")
+	case t.Progress < 0 || t.Progress > 100:
+		return nil, errors.New("progress must be between 0 and 100")
+	case len(t.TagIds) > maxTagsOnTask:
+		return nil, fmt.Errorf("a task can have at most %d tags", maxTagsOnTask)
+	}
+	if t.RecurrenceRule != "" {
+		if _, err := parseRRule(t.RecurrenceRule); err != nil {
+			return nil, fmt.Errorf("invalid recurrence_rule: %v", err)
+		}
+		if t.Deadline == nil {
+			return nil, errors.New("a recurring task needs a deadline")
+		}
+	}
+
+	task := &importTask{
+		icon:               t.Icon,
+		name:               name,
+		description:        t.Description,
+		priority:           t.Priority,
+		status:             t.Status,
+		totalPomodoros:     t.TotalPomodoros,
+		completedPomodoros: t.CompletedPomodoros,
+		originalPomodoros:  t.OriginalPomodoros,
+		progress:           t.Progress,
+		recurrenceRule:     t.RecurrenceRule,
+		series:             t.SeriesId,
+		occurrence:         max(t.Occurrence, 1),
+	}
+	if t.Deadline != nil {
+		deadline := t.Deadline.AsTime()
+		task.deadline = &deadline
+	}
+	for _, id := range t.TagIds {
+		name, ok := tags[id]
+		if !ok {
+			return nil, fmt.Errorf("task has unknown or invalid tag %s", id)
+		}
+		task.tags = append(task.tags, name)
+	}
+	slices.SortStableFunc(subtasks, func(a, b *pb.Subtask) int { return int(a.Position) - int(b.Position) })
+	for _, st := range subtasks {
+		title := strings.TrimSpace(st.Title)
+		if title == "" || len(title) > maxSubtaskTitle {
+			return nil, fmt.Errorf("subtask must have between 1 and %d characters", maxSubtaskTitle)
+		}
+		task.subtasks = append(task.subtasks, importSubtask{title: title, done: st.Done})
+	}
+	return task, nil
+}
+
+// dependsOn reports whether task depends on other, directly or through other tasks.
+func dependsOn(task, other *importTask) bool {
+	seen := map[*importTask]bool{task: true}
+	for stack := []*importTask{task}; len(stack) > 0; {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dep := range t.dependsOn {
+			if dep == other {
+				return true
+			}
+			if !seen[dep] {
+				seen[dep] = true
+				stack = append(stack, dep)
+			}
+		}
+	}
+	return false
+}
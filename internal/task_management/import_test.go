@@ -0,0 +1,156 @@
+/*
+File: internal/task_management/import_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the CSV, Todoist and Markdown import parsers.
+*/
+
+package task_management
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+)
+
+func TestImportCSVWithMapping(t *testing.T) {
+	content := "Title,List,Prio,Due,Est\n" +
+		"Write report,Work,high,2026-11-01,3\n" +
+		"Buy milk,,low,,\n" +
+		",Work,low,,\n" +
+		"Plan trip,Home,urgent,,\n" +
+		"Call mom,Home,,tomorrow,\n" +
+		"Water plants,Home,,,-1\n"
+	plan := &importPlan{defaultGroup: "Imported"}
+	err := parseCSVImport(content, &pb.CsvColumnMapping{
+		Name: "title", Group: "List", Priority: "Prio", Deadline: "Due", TotalPomodoros: "Est",
+	}, plan)
+	if err != nil {
+		t.Fatalf("parseCSVImport failed: %v", err)
+	}
+
+	if len(plan.groups) != 2 || plan.groups[0].name != "Work" || plan.groups[1].name != "Imported" {
+		t.Fatalf("Unexpected groups: %+v", plan.groups)
+	}
+	report := plan.groups[0].tasks[0]
+	if report.name != "Write report" || report.priority != pb.TaskPriority_TASK_PRIORITY_HIGH ||
+		report.totalPomodoros != 3 || report.status != pb.TaskStatus_TASK_STATUS_IDLE {
+		t.Errorf("Unexpected task: %+v", report)
+	}
+	if want := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC); report.deadline == nil || !report.deadline.Equal(want) {
+		t.Errorf("Expected deadline %v, got %v", want, report.deadline)
+	}
+
+	wantRows := []int32{4, 5, 6, 7}
+	if len(plan.errors) != len(wantRows) {
+		t.Fatalf("Expected %d row errors, got %+v", len(wantRows), plan.errors)
+	}
+	for i, row := range wantRows {
+		if plan.errors[i].Row != row {
+			t.Errorf("Expected error %d on row %d, got %+v", i, row, plan.errors[i])
+		}
+	}
+}
+
+func TestImportCSVRejectsMissingColumns(t *testing.T) {
+	if err := parseCSVImport("title\nx\n", nil, &importPlan{}); err == nil {
+		t.Error("Expected an error without a name column")
+	}
+	if err := parseCSVImport("name\nx\n", &pb.CsvColumnMapping{Deadline: "due"}, &importPlan{}); err == nil {
+		t.Error("Expected an error for a mapped column missing from the header")
+	}
+	if err := parseCSVImport("", nil, &importPlan{}); err == nil {
+		t.Error("Expected an error for an empty file")
+	}
+}
+
+func TestImportMarkdownChecklist(t *testing.T) {
+	content := "- [ ] Loose task\n" +
+		"# Groceries\n" +
+		"Some notes.\n" +
+		"- [x] Milk\n" +
+		"- [ ] Bread\n" +
+		"  - [x] Whole grain\n" +
+		"\t- [ ] Sliced\n" +
+		"- [?] Eggs\n" +
+		"* [ ]\n" +
+		"## Chores ##\n" +
+		"+ [X] Laundry\n"
+	plan := &importPlan{defaultGroup: "Inbox"}
+	parseMarkdownImport(content, plan)
+
+	if len(plan.groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", plan.groups)
+	}
+	names := []string{plan.groups[0].name, plan.groups[1].name, plan.groups[2].name}
+	if names[0] != "Inbox" || names[1] != "Groceries" || names[2] != "Chores" {
+		t.Errorf("Unexpected group names: %v", names)
+	}
+	groceries := plan.groups[1].tasks
+	if len(groceries) != 2 || groceries[0].status != pb.TaskStatus_TASK_STATUS_COMPLETED || groceries[0].row != 4 {
+		t.Fatalf("Unexpected groceries: %+v", groceries)
+	}
+	bread := groceries[1]
+	if len(bread.subtasks) != 2 || !bread.subtasks[0].done || bread.subtasks[1].done || bread.subtasks[1].title != "Sliced" {
+		t.Errorf("Unexpected subtasks: %+v", bread.subtasks)
+	}
+	if len(plan.errors) != 2 || plan.errors[0].Row != 8 || plan.errors[1].Row != 9 {
+		t.Errorf("Expected errors on lines 8 and 9, got %+v", plan.errors)
+	}
+}
+
+func TestImportTodoistBackup(t *testing.T) {
+	content := `{
+		"projects": [{"id": "p1", "name": "Work"}, {"id": 2, "name": "Home"}],
+		"items": [
+			{"id": "i1", "project_id": "p1", "content": "Ship release", "priority": 4, "child_order": 2, "due": {"date": "2026-12-01"}},
+			{"id": "i2", "project_id": "p1", "content": "Write notes", "priority": 1, "checked": true, "child_order": 1},
+			{"id": "i3", "project_id": "p1", "parent_id": "i1", "content": "Tag build", "checked": 1},
+			{"id": "i4", "project_id": "p1", "parent_id": "i3", "content": "Push tag"},
+			{"id": 5, "project_id": 2, "content": "Clean", "priority": 3, "child_order": 3},
+			{"id": "i6", "project_id": "p9", "content": "Lost"},
+			{"id": "i7", "project_id": "p1", "parent_id": "i6", "content": "Orphan"},
+			{"id": "i8", "project_id": "p1", "content": "  "}
+		]
+	}`
+	plan := &importPlan{defaultGroup: "Imported"}
+	if err := parseTodoistImport(content, plan); err != nil {
+		t.Fatalf("parseTodoistImport failed: %v", err)
+	}
+
+	if len(plan.groups) != 2 || plan.groups[0].name != "Work" || plan.groups[1].name != "Home" {
+		t.Fatalf("Unexpected groups: %+v", plan.groups)
+	}
+	work := plan.groups[0].tasks
+	if len(work) != 2 || work[0].name != "Write notes" || work[1].name != "Ship release" {
+		t.Fatalf("Expected tasks in child order, got %+v", work)
+	}
+	if work[0].status != pb.TaskStatus_TASK_STATUS_COMPLETED || work[0].priority != pb.TaskPriority_TASK_PRIORITY_LOW {
+		t.Errorf("Unexpected task: %+v", work[0])
+	}
+	release := work[1]
+	if release.priority != pb.TaskPriority_TASK_PRIORITY_HIGH || release.deadline == nil {
+		t.Errorf("Unexpected task: %+v", release)
+	}
+	if len(release.subtasks) != 2 || !release.subtasks[0].done || release.subtasks[1].title != "Push tag" {
+		t.Errorf("Expected nested items flattened into subtasks, got %+v", release.subtasks)
+	}
+	if p := plan.groups[1].tasks[0].priority; p != pb.TaskPriority_TASK_PRIORITY_MEDIUM {
+		t.Errorf("Expected Todoist priority 3 to map to medium, got %v", p)
+	}
+
+	wantRows := []int32{6, 8, 7}
+	if len(plan.errors) != len(wantRows) {
+		t.Fatalf("Expected %d item errors, got %+v", len(wantRows), plan.errors)
+	}
+	for i, row := range wantRows {
+		if plan.errors[i].Row != row {
+			t.Errorf("Expected error %d on item %d, got %+v", i, row, plan.errors[i])
+		}
+	}
+
+	if err := parseTodoistImport("not json", &importPlan{}); err == nil {
+		t.Error("Expected an error for a file that is not JSON")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+File: internal/task_management/ordering.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Manual reordering of tasks within a group and of groups per user.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MoveTask places a task right after another task of its group, or at the top.
+// Only the moved task's position changes.
+func (s *Service) MoveTask(ctx context.Context, req *pb.MoveTaskRequest) (*pb.MoveTaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	if req.AfterTaskId == req.TaskId {
+		return nil, status.Error(codes.InvalidArgument, "a task cannot be placed after itself")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, req.TaskId, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task to move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+
+	ranks := taskRankList(task.GroupId)
+	if err := ranks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+	position, err := ranks.rankAfterItem(ctx, tx, req.TaskId, req.AfterTaskId)
+	if err != nil {
+		if err == errNeighborNotFound {
+			return nil, status.Error(codes.InvalidArgument, "after_task_id must be a task in the same group")
+		}
+		log.Printf("Error ranking moved task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE tasks SET position = $1, updated_at = $2 WHERE task_id = $3`,
+		position, time.Now(), req.TaskId); err != nil {
+		log.Printf("Error moving task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+
+	task, err = getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching moved task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task")
+	}
+
+	return &pb.MoveTaskResponse{Task: task}, nil
+}
+
+// MoveTaskGroup places a group right after another group of the same user, or at the top.
+func (s *Service) MoveTaskGroup(ctx context.Context, req *pb.MoveTaskGroupRequest) (*pb.MoveTaskGroupResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+	if req.AfterGroupId == req.GroupId {
+		return nil, status.Error(codes.InvalidArgument, "a group cannot be placed after itself")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task group move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, "SELECT user_id FROM task_groups WHERE group_id = $1 AND deleted_at IS NULL FOR UPDATE", req.GroupId).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task group not found")
+		}
+		log.Printf("Error fetching task group to move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+
+	ranks := groupRankList(userID)
+	if err := ranks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task group order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+	position, err := ranks.rankAfterItem(ctx, tx, req.GroupId, req.AfterGroupId)
+	if err != nil {
+		if err == errNeighborNotFound {
+			return nil, status.Error(codes.InvalidArgument, "after_group_id must be a group of the same user")
+		}
+		log.Printf("Error ranking moved task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE task_groups SET position = $1, updated_at = $2 WHERE group_id = $3`,
+		position, time.Now(), req.GroupId); err != nil {
+		log.Printf("Error moving task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task group move: %v", err)
+		return nil, status.Error(codes.Internal, "failed to move task group")
+	}
+
+	return &pb.MoveTaskGroupResponse{Success: true, Position: position}, nil
+}
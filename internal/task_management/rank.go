@@ -0,0 +1,225 @@
+/*
+File: internal/task_management/rank.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Lexicographic ranks for user-defined ordering of tasks and task groups.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// A rank is a base-36 fraction written without the leading "0.": "i" sorts before "i5",
+// which sorts before "j". Ranks never end in '0', so there is always room between two of them
+// and moving an item only rewrites that item's rank.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// rankStepWidth is the precision at which appends step forward, allowing about
+// 36^4/2 appends before ranks start to grow.
+const rankStepWidth = 4
+
+var errNeighborNotFound = errors.New("neighbor not found in the same list")
+
+func rankDigit(r string, i int) int {
+	if i >= len(r) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, r[i])
+}
+
+// rankBetween returns a rank sorting strictly after lo and before hi.
+// An empty lo means the start of the list, an empty hi its end.
+func rankBetween(lo, hi string) string {
+	if hi == "" && lo != "" {
+		return rankAfter(lo)
+	}
+	return rankMidpoint(lo, hi)
+}
+
+// rankAfter steps lo forward at rankStepWidth precision, falling back to the midpoint
+// towards the end of the list once that precision is exhausted.
+func rankAfter(lo string) string {
+	d := []byte(lo)
+	for len(d) < rankStepWidth {
+		d = append(d, '0')
+	}
+	for i := rankStepWidth - 1; i >= 0; i-- {
+		if d[i] != 'z' {
+			d[i] = rankDigits[rankDigit(string(d), i)+1]
+			return string(d[:i+1])
+		}
+	}
+	return rankMidpoint(lo, "")
+}
+
+// rankMidpoint returns a rank roughly halfway between lo and hi. lo must sort before hi.
+func rankMidpoint(lo, hi string) string {
+	if hi != "" {
+		// Keep the common prefix and split below it.
+		n := 0
+		for n < len(hi) && rankDigit(lo, n) == rankDigit(hi, n) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(lo) {
+				rest = lo[n:]
+			}
+			return hi[:n] + rankMidpoint(rest, hi[n:])
+		}
+	}
+
+	a := rankDigit(lo, 0)
+	b := len(rankDigits)
+	if hi != "" {
+		b = rankDigit(hi, 0)
+	}
+	if b-a > 1 {
+		return string(rankDigits[(a+b)/2])
+	}
+	// Adjacent leading digits.
+	if hi != "" && len(hi) > 1 {
+		return hi[:1]
+	}
+	rest := ""
+	if len(lo) > 1 {
+		rest = lo[1:]
+	}
+	return string(rankDigits[a]) + rankMidpoint(rest, "")
+}
+
+// rankList identifies one user-ordered list: the rows of table whose scopeColumn equals
+// scope and that are not in the trash, ordered by position and then ID.
+// Table and column names are constants, never input.
+type rankList struct {
+	table, idColumn, scopeColumn string
+	scope                        string
+}
+
+// tieBreak orders rows sharing a position by ID, byte-wise like position itself.
+func (l rankList) tieBreak() string {
+	return l.idColumn + `::text COLLATE "C"`
+}
+
+// orderBy is the ORDER BY clause of the list. Every query listing or ranking its rows
+// uses it, so rows sharing a position come out in the same order everywhere.
+func (l rankList) orderBy() string {
+	return ` ORDER BY position, ` + l.tieBreak()
+}
+
+func taskRankList(groupID string) rankList {
+	return rankList{table: "tasks", idColumn: "task_id", scopeColumn: "group_id", scope: groupID}
+}
+
+func groupRankList(userID string) rankList {
+	return rankList{table: "task_groups", idColumn: "group_id", scopeColumn: "user_id", scope: userID}
+}
+
+// lock serializes rank changes in the list until the transaction ends. Callers lock
+// the task rows they change first and take this lock last, so the two kinds of lock
+// are always acquired in the same order.
+func (l rankList) lock(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "rank:"+l.table+":"+l.scope)
+	return err
+}
+
+// where selects the rows of the list.
+func (l rankList) where() string {
+	return ` WHERE ` + l.scopeColumn + ` = $1 AND deleted_at IS NULL`
+}
+
+// appendRank returns a rank placing a new item at the end of the list.
+func (l rankList) appendRank(ctx context.Context, tx *sql.Tx) (string, error) {
+	var last string
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(position), '') FROM `+l.table+l.where(), l.scope,
+	).Scan(&last)
+	if err != nil {
+		return "", err
+	}
+	return rankBetween(last, ""), nil
+}
+
+// rankAfterItem returns a rank placing movingID right after afterID, or first if afterID
+// is empty. Lists holding duplicate ranks (rows that predate ordering) are renumbered first.
+func (l rankList) rankAfterItem(ctx context.Context, tx *sql.Tx, movingID, afterID string) (string, error) {
+	for attempt := 0; ; attempt++ {
+		lo := ""
+		if afterID != "" {
+			err := tx.QueryRowContext(ctx,
+				`SELECT position FROM `+l.table+l.where()+` AND `+l.idColumn+` = $2`,
+				l.scope, afterID,
+			).Scan(&lo)
+			if err == sql.ErrNoRows {
+				return "", errNeighborNotFound
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// The item currently following afterID, ignoring the one being moved.
+		query := `SELECT position FROM ` + l.table + l.where() + ` AND ` + l.idColumn + ` <> $2`
+		args := []any{l.scope, movingID}
+		if afterID != "" {
+			query += ` AND (position, ` + l.tieBreak() + `) > ($3, $4)`
+			args = append(args, lo, afterID)
+		}
+		query += l.orderBy() + ` LIMIT 1`
+
+		var hi string
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&hi)
+		switch {
+		case err == sql.ErrNoRows:
+			return rankBetween(lo, ""), nil
+		case err != nil:
+			return "", err
+		case hi > lo:
+			return rankBetween(lo, hi), nil
+		case attempt > 0:
+			return "", errors.New("rank: list still has duplicate positions after renumbering")
+		}
+		if err := l.renumber(ctx, tx); err != nil {
+			return "", err
+		}
+	}
+}
+
+// renumber rewrites every rank in the list, keeping the current order.
+func (l rankList) renumber(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+l.idColumn+` FROM `+l.table+l.where()+l.orderBy(),
+		l.scope)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rank := ""
+	for _, id := range ids {
+		rank = rankBetween(rank, "")
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE `+l.table+` SET position = $1 WHERE `+l.idColumn+` = $2`, rank, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
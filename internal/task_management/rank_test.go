@@ -0,0 +1,75 @@
+/*
+File: internal/task_management/rank_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for lexicographic rank generation.
+*/
+
+package task_management
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func checkRank(t *testing.T, lo, got, hi string) {
+	t.Helper()
+	if got <= lo || (hi != "" && got >= hi) {
+		t.Fatalf("rankBetween(%q, %q) = %q, not strictly between", lo, hi, got)
+	}
+	if strings.HasSuffix(got, "0") {
+		t.Fatalf("rankBetween(%q, %q) = %q ends in '0'", lo, hi, got)
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	cases := [][2]string{
+		{"", ""},
+		{"", "i"},
+		{"i", ""},
+		{"i", "j"},
+		{"i", "i1"},
+		{"a", "a01"},
+		{"zzzz", ""},
+		{"", "01"},
+		{"000001i", "000002i"},
+	}
+	for _, c := range cases {
+		checkRank(t, c[0], rankBetween(c[0], c[1]), c[1])
+	}
+}
+
+func TestRankAppendsStayShort(t *testing.T) {
+	rank := ""
+	for i := 0; i < 10000; i++ {
+		next := rankBetween(rank, "")
+		checkRank(t, rank, next, "")
+		rank = next
+	}
+	if len(rank) > rankStepWidth {
+		t.Errorf("Expected appended ranks to stay within %d characters, got %q", rankStepWidth, rank)
+	}
+}
+
+func TestRankRandomInserts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ranks := []string{rankBetween("", "")}
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(ranks) + 1)
+		lo, hi := "", ""
+		if at > 0 {
+			lo = ranks[at-1]
+		}
+		if at < len(ranks) {
+			hi = ranks[at]
+		}
+		got := rankBetween(lo, hi)
+		checkRank(t, lo, got, hi)
+		ranks = slices.Insert(ranks, at, got)
+	}
+	if !slices.IsSorted(ranks) {
+		t.Errorf("Expected ranks to stay sorted")
+	}
+}
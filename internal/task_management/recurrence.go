@@ -0,0 +1,314 @@
+/*
+File: internal/task_management/recurrence.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Recurring tasks: setting a rule and materializing the next occurrence.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recurrenceBatch caps how many series one scheduler pass advances.
+const recurrenceBatch = 100
+
+// validateRecurrence checks a recurrence rule for a task; hasDeadline reports whether
+// the task has the deadline that anchors the series.
+func validateRecurrence(rule string, hasDeadline bool) error {
+	if _, err := parseRRule(rule); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !hasDeadline {
+		return status.Error(codes.InvalidArgument, "a recurring task needs a deadline")
+	}
+	return nil
+}
+
+// startSeries records a new series, or restarts one whose head task, still its only
+// occurrence, is given a rule again.
+func startSeries(ctx context.Context, q querier, seriesID string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO task_series (series_id, last_occurrence) VALUES ($1, 1)
+		ON CONFLICT (series_id) DO UPDATE SET ended_at = NULL`, seriesID)
+	return err
+}
+
+// seriesForRule returns the series a task starts when it is given a rule: its own ID,
+// unless it heads a series that has already made later occurrences. Those keep the old
+// series, so the task starts a new one rather than reviving it from the middle.
+func seriesForRule(ctx context.Context, q querier, taskID string) (string, error) {
+	var latest int
+	err := q.QueryRowContext(ctx,
+		`SELECT last_occurrence FROM task_series WHERE series_id = $1 FOR UPDATE`, taskID,
+	).Scan(&latest)
+	if err == sql.ErrNoRows || (err == nil && latest <= 1) {
+		return taskID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return uuid.NewString(), nil
+}
+
+// SetTaskRecurrence sets or clears the recurrence rule of a task. Setting a rule starts
+// a new series anchored at the task's deadline; clearing it stops the series after this task.
+func (s *Service) SetTaskRecurrence(ctx context.Context, req *pb.SetTaskRecurrenceRequest) (*pb.SetTaskRecurrenceResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting recurrence update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task recurrence")
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, req.TaskId, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for recurrence: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task recurrence")
+	}
+
+	if req.RecurrenceRule == "" {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE tasks SET recurrence_rule = '', updated_at = $1 WHERE task_id = $2`,
+			time.Now(), req.TaskId)
+	} else {
+		if err := validateRecurrence(req.RecurrenceRule, task.Deadline != nil); err != nil {
+			return nil, err
+		}
+		var seriesID string
+		seriesID, err = seriesForRule(ctx, tx, req.TaskId)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE tasks SET
+					recurrence_rule = $1,
+					recurrence_start = deadline,
+					series_id = $2,
+					occurrence = 1,
+					updated_at = $3
+				WHERE task_id = $4`,
+				req.RecurrenceRule, seriesID, time.Now(), req.TaskId)
+		}
+		if err == nil {
+			err = startSeries(ctx, tx, seriesID)
+		}
+	}
+	if err != nil {
+		log.Printf("Error updating task recurrence: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task recurrence")
+	}
+
+	task, err = getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching task after recurrence update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task recurrence")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task recurrence: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task recurrence")
+	}
+
+	return &pb.SetTaskRecurrenceResponse{Task: task}, nil
+}
+
+// materializeNext creates the occurrence that follows taskID in its series, copying
+// group, icon, name, description, priority, pomodoro estimate, tags and checklist.
+// It is a no-op for one-off tasks, ended series, and tasks that are not the latest
+// occurrence ever made, so occurrences purged from the trash are not recreated.
+// When the rule has run out the series is marked ended.
+func (s *Service) materializeNext(ctx context.Context, taskID string) error {
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		rule, groupID      string
+		seriesID           sql.NullString
+		occurrence, latest int
+		deadline, start    sql.NullTime
+		ended              bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.recurrence_rule, t.group_id, t.series_id, t.occurrence, t.deadline, t.recurrence_start,
+			COALESCE(s.last_occurrence, t.occurrence), s.ended_at IS NOT NULL
+		FROM tasks t
+		LEFT JOIN task_series s ON s.series_id = t.series_id
+		WHERE t.task_id = $1 AND t.deleted_at IS NULL
+		FOR UPDATE OF t`, taskID,
+	).Scan(&rule, &groupID, &seriesID, &occurrence, &deadline, &start, &latest, &ended)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if rule == "" || !seriesID.Valid || !deadline.Valid || ended || occurrence < latest {
+		return nil
+	}
+	if !start.Valid {
+		start = deadline
+	}
+
+	r, err := parseRRule(rule)
+	if err != nil {
+		// Only valid rules are stored; treat a bad one as the end of the series.
+		log.Printf("Task %s has an unparsable recurrence rule %q: %v", taskID, rule, err)
+		return nil
+	}
+	nextDeadline, _, ok := r.next(start.Time, deadline.Time)
+	if !ok || (r.count > 0 && occurrence >= r.count) {
+		// Keep the scheduler from picking the series up again.
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE task_series SET ended_at = $1 WHERE series_id = $2`,
+			time.Now(), seriesID.String); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	// The next occurrence goes to the bottom of the group.
+	ranks := taskRankList(groupID)
+	if err := ranks.lock(ctx, tx); err != nil {
+		return err
+	}
+	position, err := ranks.appendRank(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	nextID := uuid.NewString()
+	now := time.Now()
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO tasks (
+			task_id, user_id, group_id, icon, name, description,
+			priority, status, total_pomodoros, completed_pomodoros, progress,
+			deadline, created_at, updated_at,
+			recurrence_rule, recurrence_start, series_id, occurrence, position,
+			original_pomodoros
+		)
+		SELECT $1, user_id, group_id, icon, name, description,
+			priority, $2, total_pomodoros, 0, 0,
+			$3, $4, $4,
+			recurrence_rule, recurrence_start, series_id, occurrence + 1, $6,
+			total_pomodoros
+		FROM tasks WHERE task_id = $5
+		ON CONFLICT (series_id, occurrence) WHERE series_id IS NOT NULL DO NOTHING`,
+		nextID,
+		helper.TaskStatusDbEnumToString(pb.TaskStatus_TASK_STATUS_IDLE),
+		nextDeadline,
+		now,
+		taskID,
+		position,
+	)
+	if err != nil {
+		return err
+	}
+	if inserted, _ := res.RowsAffected(); inserted == 0 {
+		return nil // already materialized
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_series (series_id, last_occurrence) VALUES ($1, $2)
+		ON CONFLICT (series_id) DO UPDATE
+		SET last_occurrence = GREATEST(task_series.last_occurrence, EXCLUDED.last_occurrence)`,
+		seriesID.String, occurrence+1); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_tags (task_id, tag_id)
+		SELECT $1, tag_id FROM task_tags WHERE task_id = $2`, nextID, taskID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO subtasks (subtask_id, task_id, title, done, position, created_at, updated_at)
+		SELECT gen_random_uuid(), $1, title, FALSE, position, $3, $3
+		FROM subtasks WHERE task_id = $2`, nextID, taskID, now); err != nil {
+		return err
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, groupID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("Materialized occurrence %d of series %s as task %s (due %s)",
+		occurrence+1, seriesID.String, nextID, nextDeadline.Format(time.RFC3339))
+	return nil
+}
+
+// RunRecurrenceScheduler periodically materializes the next occurrence of recurring
+// tasks whose deadline has passed, even if they were never completed, until ctx is cancelled.
+// Series in archived groups are left alone.
+func (s *Service) RunRecurrenceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.advanceRecurrences(ctx)
+		}
+	}
+}
+
+// advanceRecurrences runs one scheduler pass over the latest occurrence of each series
+// that has not ended.
+func (s *Service) advanceRecurrences(ctx context.Context) {
+	rows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT t.task_id
+		FROM tasks t
+		JOIN task_series s ON s.series_id = t.series_id
+		WHERE t.recurrence_rule <> '' AND t.deadline < $1
+		  AND t.deleted_at IS NULL
+		  AND s.ended_at IS NULL AND t.occurrence = s.last_occurrence
+		  AND NOT EXISTS (
+			SELECT 1 FROM task_groups g
+			WHERE g.group_id = t.group_id AND g.archived_at IS NOT NULL
+		  )
+		ORDER BY t.deadline
+		LIMIT $2`, time.Now(), recurrenceBatch)
+	if err != nil {
+		log.Printf("Error querying due recurring tasks: %v", err)
+		return
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Error scanning due recurring task: %v", err)
+			rows.Close()
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := s.materializeNext(ctx, id); err != nil {
+			log.Printf("Error materializing next occurrence of task %s: %v", id, err)
+		}
+	}
+}
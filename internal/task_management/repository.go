@@ -0,0 +1,228 @@
+/*
+File: internal/task_management/repository.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Shared SQL helpers for reading tasks.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// completedAtUpdate is the SET clause keeping completed_at in step with the status written
+// as statusArg: stamped with nowArg when the task becomes completed, kept while it stays
+// completed and cleared when it is reopened.
+func completedAtUpdate(statusArg, nowArg string) string {
+	return `completed_at = CASE WHEN ` + statusArg + ` = 'completed' THEN COALESCE(completed_at, ` + nowArg + `) END`
+}
+
+// completedAtValue is the completed_at of a task inserted with status s.
+func completedAtValue(s pb.TaskStatus, now time.Time) any {
+	if s == pb.TaskStatus_TASK_STATUS_COMPLETED {
+		return now
+	}
+	return nil
+}
+
+// taskColumns lists the tasks columns read by scanTask, in scan order.
+// Subtask counts, tag IDs, dependencies and credited sessions are computed per row, so queries must
+// select FROM tasks unaliased.
+const taskColumns = `task_id, user_id, group_id, icon, name, description,
+		priority, status, total_pomodoros, completed_pomodoros, progress,
+		deadline, created_at, updated_at,
+		(SELECT COUNT(*) FROM subtasks st WHERE st.task_id = tasks.task_id AND st.done),
+		(SELECT COUNT(*) FROM subtasks st WHERE st.task_id = tasks.task_id),
+		ARRAY(SELECT tt.tag_id::text FROM task_tags tt WHERE tt.task_id = tasks.task_id ORDER BY tt.tag_id),
+		recurrence_rule, series_id, occurrence,
+		ARRAY(
+			SELECT d.depends_on_id::text FROM task_dependencies d JOIN tasks dt ON dt.task_id = d.depends_on_id
+			WHERE d.task_id = tasks.task_id AND dt.deleted_at IS NULL ORDER BY d.depends_on_id
+		),
+		EXISTS (
+			SELECT 1 FROM task_dependencies d JOIN tasks dt ON dt.task_id = d.depends_on_id
+			WHERE d.task_id = tasks.task_id AND dt.status <> 'completed' AND dt.deleted_at IS NULL
+		),
+		position, deleted_at, original_pomodoros,
+		(SELECT COUNT(*) FROM task_pomodoro_credits c WHERE c.task_id = tasks.task_id),
+		(SELECT COALESCE(SUM(c.focused_seconds), 0) / 60 FROM task_pomodoro_credits c WHERE c.task_id = tasks.task_id),
+		completed_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// extraScanner scans the columns selected after taskColumns into extra.
+type extraScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// scanTask reads one row selected with taskColumns.
+func scanTask(row rowScanner) (*pb.Task, error) {
+	var (
+		task                 pb.Task
+		priorityLabel        string
+		statusLabel          string
+		deadlineNT           sql.NullTime
+		createdAt, updatedAt time.Time
+		seriesID             sql.NullString
+		deletedAt            sql.NullTime
+		completedAt          sql.NullTime
+	)
+	if err := row.Scan(
+		&task.TaskId,
+		&task.UserId,
+		&task.GroupId,
+		&task.Icon,
+		&task.Name,
+		&task.Description,
+		&priorityLabel,
+		&statusLabel,
+		&task.TotalPomodoros,
+		&task.CompletedPomodoros,
+		&task.Progress,
+		&deadlineNT,
+		&createdAt,
+		&updatedAt,
+		&task.CompletedSubtasks,
+		&task.TotalSubtasks,
+		pq.Array(&task.TagIds),
+		&task.RecurrenceRule,
+		&seriesID,
+		&task.Occurrence,
+		pq.Array(&task.DependsOn),
+		&task.Blocked,
+		&task.Position,
+		&deletedAt,
+		&task.OriginalPomodoros,
+		&task.CreditedPomodoros,
+		&task.FocusedMinutes,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	task.Priority = helper.TaskPriorityDbStringToEnum(priorityLabel)
+	task.Status = helper.TaskStatusDbStringToEnum(statusLabel)
+	if deadlineNT.Valid {
+		task.Deadline = timestamppb.New(deadlineNT.Time)
+	}
+	task.CreatedAt = timestamppb.New(createdAt)
+	task.UpdatedAt = timestamppb.New(updatedAt)
+	task.SeriesId = seriesID.String
+	if deletedAt.Valid {
+		task.DeletedAt = timestamppb.New(deletedAt.Time)
+	}
+	if completedAt.Valid {
+		task.CompletedAt = timestamppb.New(completedAt.Time)
+	}
+
+	return &task, nil
+}
+
+// getTask loads a task by ID; tasks in the trash are not found.
+// Pass forUpdate inside a transaction to lock the row.
+func getTask(ctx context.Context, q querier, taskID string, forUpdate bool) (*pb.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE task_id = $1 AND deleted_at IS NULL`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	return scanTask(q.QueryRowContext(ctx, query, taskID))
+}
+
+// taskGroupColumns lists the task_groups columns read by scanTaskGroup, in scan order.
+const taskGroupColumns = `group_id, user_id, icon, name, description, deadline,
+		priority, status, completed_tasks, total_tasks,
+		created_at, updated_at, position, deleted_at, archived_at`
+
+// scanTaskGroup reads one row selected with taskGroupColumns.
+func scanTaskGroup(row rowScanner) (*pb.TaskGroup, error) {
+	var (
+		group                pb.TaskGroup
+		priorityLabel        string
+		statusLabel          string
+		deadline, deletedAt  sql.NullTime
+		archivedAt           sql.NullTime
+		createdAt, updatedAt time.Time
+	)
+	if err := row.Scan(
+		&group.GroupId,
+		&group.UserId,
+		&group.Icon,
+		&group.Name,
+		&group.Description,
+		&deadline,
+		&priorityLabel,
+		&statusLabel,
+		&group.CompletedTasks,
+		&group.TotalTasks,
+		&createdAt,
+		&updatedAt,
+		&group.Position,
+		&deletedAt,
+		&archivedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	group.Priority = helper.TaskGroupPriorityDbStringToEnum(priorityLabel)
+	group.Status = helper.TaskGroupStatusDbStringToEnum(statusLabel)
+	if deadline.Valid {
+		group.Deadline = timestamppb.New(deadline.Time)
+	}
+	group.CreatedAt = timestamppb.New(createdAt)
+	group.UpdatedAt = timestamppb.New(updatedAt)
+	if deletedAt.Valid {
+		group.DeletedAt = timestamppb.New(deletedAt.Time)
+	}
+	if archivedAt.Valid {
+		group.ArchivedAt = timestamppb.New(archivedAt.Time)
+	}
+	return &group, nil
+}
+
+// queryArgs collects the arguments of a query built from optional filters.
+type queryArgs []any
+
+// add appends v and returns its placeholder.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isInvalidTextRepresentation reports whether err is a Postgres cast failure,
+// as raised by a tampered page token.
+func isInvalidTextRepresentation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == "22P02" || pqErr.Code == "22007" || pqErr.Code == "22008")
+}
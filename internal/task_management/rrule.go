@@ -0,0 +1,296 @@
+/*
+File: internal/task_management/rrule.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Parser and occurrence generator for the RFC 5545 RRULE subset used by recurring tasks.
+*/
+
+package task_management
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRRulePeriods bounds how many periods (days, weeks or months) the generator
+// walks before giving up, so a rule that never matches cannot loop forever.
+const maxRRulePeriods = 5000
+
+var errInvalidRRule = errors.New("invalid recurrence rule")
+
+type rruleFreq int
+
+const (
+	freqDaily rruleFreq = iota + 1
+	freqWeekly
+	freqMonthly
+)
+
+// weekdayRule is one BYDAY entry. ordinal is only meaningful for MONTHLY rules:
+// 0 means every such weekday, 1 the first, -1 the last, and so on.
+type weekdayRule struct {
+	ordinal int
+	day     time.Weekday
+}
+
+// rrule is a parsed recurrence rule. Supported parts: FREQ (DAILY, WEEKLY, MONTHLY),
+// INTERVAL, BYDAY, COUNT and UNTIL. Weeks start on Monday.
+type rrule struct {
+	freq     rruleFreq
+	interval int
+	byDay    []weekdayRule
+	count    int       // 0 = unbounded
+	until    time.Time // zero = unbounded
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseRRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted.
+func parseRRule(s string) (*rrule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty", errInvalidRRule)
+	}
+
+	r := &rrule{interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", errInvalidRRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: duplicate %s", errInvalidRRule, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY":
+				r.freq = freqDaily
+			case "WEEKLY":
+				r.freq = freqWeekly
+			case "MONTHLY":
+				r.freq = freqMonthly
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", errInvalidRRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 366 {
+				return nil, fmt.Errorf("%w: INTERVAL must be between 1 and 366", errInvalidRRule)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be positive", errInvalidRRule)
+			}
+			r.count = n
+		case "UNTIL":
+			t, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, err
+			}
+			r.until = t
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, err := parseWeekdayRule(d)
+				if err != nil {
+					return nil, err
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", errInvalidRRule, key)
+		}
+	}
+
+	if r.freq == 0 {
+		return nil, fmt.Errorf("%w: FREQ is required", errInvalidRRule)
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", errInvalidRRule)
+	}
+	if r.freq != freqMonthly {
+		for _, wd := range r.byDay {
+			if wd.ordinal != 0 {
+				return nil, fmt.Errorf("%w: BYDAY ordinals are only allowed with FREQ=MONTHLY", errInvalidRRule)
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseRRuleTime accepts the UNTIL forms 20250930, 20250930T170000 and 20250930T170000Z.
+func parseRRuleTime(v string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day.
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: bad UNTIL %q", errInvalidRRule, v)
+}
+
+func parseWeekdayRule(s string) (weekdayRule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return weekdayRule{}, fmt.Errorf("%w: bad BYDAY %q", errInvalidRRule, s)
+	}
+	day, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return weekdayRule{}, fmt.Errorf("%w: bad BYDAY %q", errInvalidRRule, s)
+	}
+	wd := weekdayRule{day: day}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return weekdayRule{}, fmt.Errorf("%w: bad BYDAY %q", errInvalidRRule, s)
+		}
+		wd.ordinal = n
+	}
+	return wd, nil
+}
+
+// next returns the first occurrence strictly after `after` in the series starting at
+// dtstart, together with its 1-based index in the series. ok is false when the series
+// has ended (COUNT or UNTIL reached). Occurrences keep dtstart's time of day and location.
+func (r *rrule) next(dtstart, after time.Time) (t time.Time, index int, ok bool) {
+	index = 0
+	found := false
+	r.each(dtstart, func(occ time.Time) bool {
+		index++
+		if r.count > 0 && index > r.count {
+			return false
+		}
+		if !r.until.IsZero() && occ.After(r.until) {
+			return false
+		}
+		if occ.After(after) {
+			t, found = occ, true
+			return false
+		}
+		return true
+	})
+	return t, index, found
+}
+
+// each calls yield with the occurrences of the rule, in order, from dtstart onwards,
+// until yield returns false or maxRRulePeriods periods have been scanned.
+func (r *rrule) each(dtstart time.Time, yield func(time.Time) bool) {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, dtstart.Nanosecond(), loc)
+	}
+
+	switch r.freq {
+	case freqDaily:
+		for i := 0; i < maxRRulePeriods; i += r.interval {
+			occ := at(y, m, d+i)
+			if len(r.byDay) > 0 && !r.hasWeekday(occ.Weekday()) {
+				continue
+			}
+			if !yield(occ) {
+				return
+			}
+		}
+
+	case freqWeekly:
+		days := r.weekdays(dtstart.Weekday())
+		// Monday of dtstart's week.
+		monday := d - (int(dtstart.Weekday())+6)%7
+		for w := 0; w < maxRRulePeriods; w += r.interval {
+			for _, wd := range days {
+				occ := at(y, m, monday+7*w+(int(wd)+6)%7)
+				if occ.Before(dtstart) {
+					continue
+				}
+				if !yield(occ) {
+					return
+				}
+			}
+		}
+
+	case freqMonthly:
+		for i := 0; i < maxRRulePeriods; i += r.interval {
+			first := at(y, m+time.Month(i), 1)
+			for _, day := range r.monthDays(first.Year(), first.Month(), d) {
+				occ := at(first.Year(), first.Month(), day)
+				if occ.Before(dtstart) {
+					continue
+				}
+				if !yield(occ) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *rrule) hasWeekday(day time.Weekday) bool {
+	for _, wd := range r.byDay {
+		if wd.day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdays returns the BYDAY weekdays in week order (Monday first), or def if BYDAY is unset.
+func (r *rrule) weekdays(def time.Weekday) []time.Weekday {
+	if len(r.byDay) == 0 {
+		return []time.Weekday{def}
+	}
+	var days []time.Weekday
+	for _, wd := range r.byDay {
+		if !slices.Contains(days, wd.day) {
+			days = append(days, wd.day)
+		}
+	}
+	slices.SortFunc(days, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+	return days
+}
+
+// monthDays returns, in ascending order, the days of the given month matched by the rule.
+// Without BYDAY that is dtstart's day of month, skipped in months that are too short.
+func (r *rrule) monthDays(year int, month time.Month, dtstartDay int) []int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.byDay) == 0 {
+		if dtstartDay > last {
+			return nil
+		}
+		return []int{dtstartDay}
+	}
+
+	var days []int
+	for day := 1; day <= last; day++ {
+		weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+		nth := (day-1)/7 + 1              // 1st, 2nd, ... such weekday of the month
+		nthFromEnd := -((last-day)/7 + 1) // -1 for the last, -2 for the one before, ...
+		for _, wd := range r.byDay {
+			if wd.day == weekday && (wd.ordinal == 0 || wd.ordinal == nth || wd.ordinal == nthFromEnd) {
+				days = append(days, day)
+				break
+			}
+		}
+	}
+	return days
+}
@@ -0,0 +1,94 @@
+/*
+File: internal/task_management/rrule_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for the recurrence rule parser and generator.
+*/
+
+package task_management
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	r, err := parseRRule("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=6")
+	if err != nil {
+		t.Fatalf("parseRRule failed: %v", err)
+	}
+	if r.freq != freqWeekly || r.interval != 2 || r.count != 6 || len(r.byDay) != 2 {
+		t.Errorf("Unexpected rule %+v", r)
+	}
+
+	invalid := []string{
+		"",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250930",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	}
+	for _, s := range invalid {
+		if _, err := parseRRule(s); !errors.Is(err, errInvalidRRule) {
+			t.Errorf("parseRRule(%q): expected errInvalidRRule, got %v", s, err)
+		}
+	}
+}
+
+func TestRRuleNext(t *testing.T) {
+	// Monday 1 September 2025, 09:00.
+	dtstart := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 9, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		rule  string
+		after time.Time
+		want  time.Time
+		index int
+		ended bool
+	}{
+		{rule: "FREQ=DAILY", after: dtstart, want: day(9, 2), index: 2},
+		{rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", after: day(9, 5), want: day(9, 8), index: 6},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,WE", after: day(9, 3), want: day(9, 8), index: 3},
+		{rule: "FREQ=WEEKLY;INTERVAL=2", after: dtstart, want: day(9, 15), index: 2},
+		{rule: "FREQ=MONTHLY", after: dtstart, want: day(10, 1), index: 2},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR", after: dtstart, want: day(9, 26), index: 1},
+		{rule: "FREQ=MONTHLY;BYDAY=2TU", after: day(9, 9), want: day(10, 14), index: 2},
+		{rule: "FREQ=DAILY;COUNT=3", after: day(9, 2), want: day(9, 3), index: 3},
+		{rule: "FREQ=DAILY;COUNT=3", after: day(9, 3), ended: true},
+		{rule: "FREQ=DAILY;UNTIL=20250903", after: day(9, 3), ended: true},
+	}
+	for _, c := range cases {
+		r, err := parseRRule(c.rule)
+		if err != nil {
+			t.Fatalf("parseRRule(%q) failed: %v", c.rule, err)
+		}
+		got, index, ok := r.next(dtstart, c.after)
+		if c.ended {
+			if ok {
+				t.Errorf("%s after %v: expected series to end, got %v", c.rule, c.after, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(c.want) || index != c.index {
+			t.Errorf("%s after %v: expected %v (#%d), got %v (#%d, ok=%v)", c.rule, c.after, c.want, c.index, got, index, ok)
+		}
+	}
+}
+
+func TestRRuleMonthlySkipsShortMonths(t *testing.T) {
+	dtstart := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	r, err := parseRRule("FREQ=MONTHLY")
+	if err != nil {
+		t.Fatalf("parseRRule failed: %v", err)
+	}
+	got, _, ok := r.next(dtstart, dtstart)
+	if want := time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+File: internal/task_management/search.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Full-text search over task names and descriptions.
+*/
+
+package task_management
+
+import (
+	"context"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+	maxSearchQuery        = 256
+	maxSearchTerms        = 16
+)
+
+// searchOrdering is the only order search results come in, recorded in page tokens.
+var searchOrdering = helper.PageOrdering("search_rank", true)
+
+// ts_headline returns the stored text as is, so matches are first delimited with
+// private-use characters, stripped from the text beforehand, and turned into <mark>
+// only after the text is HTML-escaped. Descriptions are cut down to a few fragments.
+const (
+	headlineStart              = "\uE000"
+	headlineStop               = "\uE001"
+	nameHeadlineOptions        = `StartSel=` + headlineStart + `, StopSel=` + headlineStop + `, HighlightAll=true`
+	descriptionHeadlineOptions = `StartSel=` + headlineStart + `, StopSel=` + headlineStop + `, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// markHeadline HTML-escapes a ts_headline result and wraps its matches in <mark>.
+func markHeadline(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+// prefixTSQuery turns free text into a tsquery matching every word as a prefix,
+// e.g. "Quarterly rep" becomes "quarterly:* & rep:*". Only letters and digits are
+// kept, so the result is always valid tsquery syntax. It returns "" if no word remains.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// SearchTasks finds a user's tasks by the words in their name and description,
+// most relevant first.
+func (s *Service) SearchTasks(ctx context.Context, req *pb.SearchTasksRequest) (*pb.SearchTasksResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if len(req.Query) > maxSearchQuery {
+		return nil, status.Errorf(codes.InvalidArgument, "query must be at most %d characters", maxSearchQuery)
+	}
+	tsQuery := prefixTSQuery(req.Query)
+	if tsQuery == "" {
+		return nil, status.Error(codes.InvalidArgument, "query must contain at least one word")
+	}
+	if req.GroupId != "" {
+		if _, err := uuid.Parse(req.GroupId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "group_id must be a valid UUID")
+		}
+	}
+	if req.DeadlineFrom != nil && req.DeadlineTo != nil && !req.DeadlineTo.AsTime().After(req.DeadlineFrom.AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "deadline_to must be after deadline_from")
+	}
+
+	pageSize := helper.ClampPageSize(req.PageSize, defaultSearchPageSize, maxSearchPageSize)
+
+	args := queryArgs{req.UserId}
+	tsq := `to_tsquery('simple', ` + args.add(tsQuery) + `)`
+	rank := `ts_rank(search_vector, ` + tsq + `)`
+
+	marks := args.add(headlineStart + headlineStop)
+	query := `SELECT ` + taskColumns + `, ` + rank + ` AS search_rank,
+			ts_headline('simple', translate(name, ` + marks + `, ''), ` + tsq + `, ` + args.add(nameHeadlineOptions) + `),
+			ts_headline('simple', translate(description, ` + marks + `, ''), ` + tsq + `, ` + args.add(descriptionHeadlineOptions) + `)
+		FROM tasks
+		WHERE user_id = $1 AND deleted_at IS NULL AND search_vector @@ ` + tsq
+	if req.GroupId != "" {
+		query += ` AND group_id = ` + args.add(req.GroupId)
+	}
+	query += statusFilter(&args, req.Statuses) +
+		priorityFilter(&args, req.Priorities) +
+		timeRangeFilter(&args, "deadline", req.DeadlineFrom, req.DeadlineTo) +
+		archivedGroupFilter(req.IncludeArchived || req.GroupId != "")
+
+	if req.PageToken != "" {
+		keys, err := helper.DecodePageToken(req.PageToken, searchOrdering, 2)
+		if err == helper.ErrPageTokenOrdering {
+			return nil, status.Error(codes.InvalidArgument, "page_token was issued for a different sort order")
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterRank, err := strconv.ParseFloat(keys[0], 32)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		// Rank descending, then task_id ascending.
+		r, id := args.add(float32(afterRank)), args.add(keys[1])
+		query += ` AND (` + rank + ` < ` + r + `::real OR (` + rank + ` = ` + r + `::real AND task_id > ` + id + `))`
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	query += ` ORDER BY search_rank DESC, task_id LIMIT ` + args.add(pageSize+1)
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error searching tasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to search tasks")
+	}
+	defer rows.Close()
+
+	var results []*pb.TaskSearchResult
+	for rows.Next() {
+		var result pb.TaskSearchResult
+		task, err := scanTask(extraScanner{rows, []any{&result.Rank, &result.NameHighlight, &result.DescriptionSnippet}})
+		if err != nil {
+			log.Printf("Error scanning search result: %v", err)
+			return nil, status.Error(codes.Internal, "failed to search tasks")
+		}
+		result.Task = task
+		result.NameHighlight = markHeadline(result.NameHighlight)
+		result.DescriptionSnippet = markHeadline(result.DescriptionSnippet)
+		results = append(results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to search tasks")
+	}
+
+	resp := &pb.SearchTasksResponse{}
+	if len(results) > int(pageSize) {
+		results = results[:pageSize]
+		last := results[len(results)-1]
+		resp.NextPageToken = helper.EncodePageToken(searchOrdering,
+			strconv.FormatFloat(float64(last.Rank), 'g', -1, 32), last.Task.TaskId)
+	}
+	resp.Results = results
+	return resp, nil
+}
@@ -0,0 +1,41 @@
+/*
+File: internal/task_management/search_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for search query parsing and highlighting.
+*/
+
+package task_management
+
+import "testing"
+
+func TestPrefixTSQuery(t *testing.T) {
+	cases := map[string]string{
+		"Quarterly rep":        "quarterly:* & rep:*",
+		"  deep-work!  ":       "deep:* & work:*",
+		"a:* | b & !c":         "a:* & b:* & c:*",
+		"Báo cáo":              "báo:* & cáo:*",
+		"'); DROP TABLE tasks": "drop:* & table:* & tasks:*",
+		"!!! ???":              "",
+		"":                     "",
+	}
+	for in, want := range cases {
+		if got := prefixTSQuery(in); got != want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMarkHeadline(t *testing.T) {
+	cases := map[string]string{
+		headlineStart + "Quarterly" + headlineStop + " report":                  "<mark>Quarterly</mark> report",
+		"<img src=x onerror=alert(1)> " + headlineStart + "plan" + headlineStop: "&lt;img src=x onerror=alert(1)&gt; <mark>plan</mark>",
+		`Tom & "Jerry"`: "Tom &amp; &#34;Jerry&#34;",
+		"":              "",
+	}
+	for in, want := range cases {
+		if got := markHeadline(in); got != want {
+			t.Errorf("markHeadline(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
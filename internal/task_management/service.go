@@ -28,6 +28,11 @@ type Service struct {
 	db *database.Connections
 }
 
+const (
+	defaultTaskPageSize = 100
+	maxTaskPageSize     = 500
+)
+
 func NewService(db *database.Connections) *Service {
 	return &Service{db: db}
 }
@@ -46,8 +51,15 @@ func (s *Service) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*p
 		return nil, status.Error(codes.InvalidArgument, "title is required")
 	}
 
-	if req.TotalPomodoros <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "total_pomodoros must be greater than 0")
+	// A task without a pomodoro estimate tracks progress through its checklist instead.
+	if req.TotalPomodoros < 0 {
+		return nil, status.Error(codes.InvalidArgument, "total_pomodoros must not be negative")
+	}
+
+	if req.RecurrenceRule != "" {
+		if err := validateRecurrence(req.RecurrenceRule, req.Deadline != nil); err != nil {
+			return nil, err
+		}
 	}
 
 	taskId := uuid.NewString()
@@ -75,18 +87,50 @@ func (s *Service) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*p
 		Deadline:           req.Deadline,
 		CreatedAt:          timestamppb.New(now),
 		UpdatedAt:          timestamppb.New(now),
+		RecurrenceRule:     req.RecurrenceRule,
+		Occurrence:         1,
+		OriginalPomodoros:  req.TotalPomodoros,
+	}
+
+	// A recurring task starts its own series, anchored at its deadline.
+	var seriesID, recurrenceStart any
+	if req.RecurrenceRule != "" {
+		newTask.SeriesId = taskId
+		seriesID = taskId
+		recurrenceStart = deadlineVal
 	}
 
 	priorityLabel := helper.TaskPriorityDbEnumToString(req.Priority)
 	statusLabel := helper.TaskStatusDbEnumToString(req.Status)
 
-	_, err := s.db.TaskDB.ExecContext(
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task creation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task")
+	}
+	defer tx.Rollback()
+
+	// New tasks go to the bottom of their group.
+	ranks := taskRankList(req.GroupId)
+	if err := ranks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task")
+	}
+	newTask.Position, err = ranks.appendRank(ctx, tx)
+	if err != nil {
+		log.Printf("Error ranking new task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task")
+	}
+
+	_, err = tx.ExecContext(
 		ctx,
 		`INSERT INTO tasks (
             task_id, user_id, group_id, icon, name, description,
             priority, status, total_pomodoros, completed_pomodoros, progress,
-            deadline, created_at, updated_at
-        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+            deadline, created_at, updated_at,
+            recurrence_rule, recurrence_start, series_id, occurrence, position,
+            original_pomodoros, completed_at
+        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$9,$20)`,
 		newTask.TaskId,
 		newTask.UserId,
 		newTask.GroupId,
@@ -101,12 +145,32 @@ func (s *Service) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*p
 		deadlineVal, // nil/NULL or time.Time
 		now,
 		now,
+		newTask.RecurrenceRule,
+		recurrenceStart,
+		seriesID,
+		newTask.Occurrence,
+		newTask.Position,
+		completedAtValue(req.Status, now),
 	)
 
 	if err != nil {
 		log.Printf("Error creating task: %v", err)
 		return nil, status.Error(codes.Internal, "failed to create task")
 	}
+	if seriesID != nil {
+		if err := startSeries(ctx, tx, taskId); err != nil {
+			log.Printf("Error starting task series: %v", err)
+			return nil, status.Error(codes.Internal, "failed to create task")
+		}
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, req.GroupId); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task")
+	}
 
 	return &pb.CreateTaskResponse{Task: newTask}, nil
 }
@@ -115,68 +179,84 @@ func (s *Service) GetTasks(ctx context.Context, req *pb.GetTasksRequest) (*pb.Ge
 	if req.UserId == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
+	// Malformed IDs are rejected here, so a cast error from the query can only come
+	// from the page token.
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a valid UUID")
+	}
+	if req.GroupId != "" {
+		if _, err := uuid.Parse(req.GroupId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "group_id must be a valid UUID")
+		}
+	}
+	for _, id := range req.TagIds {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "tag_ids must be valid UUIDs")
+		}
+	}
+	if req.DeadlineAfter != nil && req.DeadlineBefore != nil && !req.DeadlineBefore.AsTime().After(req.DeadlineAfter.AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "deadline_before must be after deadline_after")
+	}
+
+	pageSize := helper.ClampPageSize(req.PageSize, defaultTaskPageSize, maxTaskPageSize)
+	keys := taskOrdering(req.SortBy)
+	desc := req.Order == pb.SortOrder_SORT_ORDER_DESC
+	ordering := helper.PageOrdering(req.SortBy.String(), desc)
+
+	var args queryArgs
+	query := `SELECT ` + taskColumns + `, ` + keyColumns(keys) + ` FROM tasks WHERE deleted_at IS NULL` +
+		` AND user_id = ` + args.add(req.UserId)
+	if req.GroupId != "" {
+		query += ` AND group_id = ` + args.add(req.GroupId)
+	}
+	query += tagFilter(&args, req.TagIds, req.TagMatch) +
+		statusFilter(&args, req.Statuses) +
+		priorityFilter(&args, req.Priorities) +
+		timeRangeFilter(&args, "deadline", req.DeadlineAfter, req.DeadlineBefore) +
+		timeRangeFilter(&args, "updated_at", req.UpdatedSince, nil) +
+		archivedGroupFilter(req.IncludeArchived || req.GroupId != "")
+	if req.PageToken != "" {
+		values, err := helper.DecodePageToken(req.PageToken, ordering, len(keys))
+		if err == helper.ErrPageTokenOrdering {
+			return nil, status.Error(codes.InvalidArgument, "page_token was issued for a different sort order")
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		query += keysetFilter(&args, keys, values, desc)
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	query += orderBy(keys, desc) + ` LIMIT ` + args.add(pageSize+1)
 
-	rows, err := s.db.TaskDB.QueryContext(ctx, `
-        SELECT
-            task_id, group_id, icon, name, description,
-            priority, status, total_pomodoros, completed_pomodoros, progress,
-            deadline, created_at, updated_at
-        FROM tasks
-        WHERE user_id = $1
-    `, req.UserId)
+	rows, err := s.db.TaskDB.QueryContext(ctx, query, args...)
 	if err != nil {
+		if isInvalidTextRepresentation(err) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
 		log.Printf("Error fetching tasks: %v", err)
 		return nil, status.Error(codes.Internal, "failed to fetch tasks")
 	}
 	defer rows.Close()
 
-	var tasks []*pb.Task
+	var (
+		tasks   []*pb.Task
+		lastKey []string
+	)
 	for rows.Next() {
-		var (
-			task                 pb.Task
-			priorityLabel        string
-			statusLabel          string
-			totalPomodoros       int32
-			completedPomodoros   int32
-			progress             int32
-			deadlineNT           sql.NullTime
-			createdAt, updatedAt time.Time
-		)
-
-		if err := rows.Scan(
-			&task.TaskId,
-			&task.GroupId,
-			&task.Icon,
-			&task.Name,
-			&task.Description,
-			&priorityLabel,
-			&statusLabel,
-			&totalPomodoros,
-			&completedPomodoros,
-			&progress,
-			&deadlineNT,
-			&createdAt,
-			&updatedAt,
-		); err != nil {
+		values := make([]string, len(keys))
+		extra := make([]any, len(keys))
+		for i := range values {
+			extra[i] = &values[i]
+		}
+		task, err := scanTask(extraScanner{rows, extra})
+		if err != nil {
 			log.Printf("Error scanning task: %v", err)
 			return nil, status.Error(codes.Internal, "failed to scan task")
 		}
-
-		task.UserId = req.UserId
-		task.Priority = helper.TaskPriorityDbStringToEnum(priorityLabel)
-		task.Status = helper.TaskStatusDbStringToEnum(statusLabel)
-		task.TotalPomodoros = totalPomodoros
-		task.CompletedPomodoros = completedPomodoros
-		task.Progress = progress
-		if deadlineNT.Valid {
-			task.Deadline = timestamppb.New(deadlineNT.Time)
-		} else {
-			task.Deadline = nil
+		if len(tasks) < int(pageSize) {
+			lastKey = values
 		}
-		task.CreatedAt = timestamppb.New(createdAt)
-		task.UpdatedAt = timestamppb.New(updatedAt)
-
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -184,7 +264,13 @@ func (s *Service) GetTasks(ctx context.Context, req *pb.GetTasksRequest) (*pb.Ge
 		return nil, status.Error(codes.Internal, "failed to fetch tasks")
 	}
 
-	return &pb.GetTasksResponse{Tasks: tasks}, nil
+	resp := &pb.GetTasksResponse{}
+	if len(tasks) > int(pageSize) {
+		tasks = tasks[:pageSize]
+		resp.NextPageToken = helper.EncodePageToken(ordering, lastKey...)
+	}
+	resp.Tasks = tasks
+	return resp, nil
 }
 
 func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.UpdateTaskResponse, error) {
@@ -192,6 +278,25 @@ func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*p
 		return nil, status.Error(codes.InvalidArgument, "task_id is required")
 	}
 
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update task")
+	}
+	defer tx.Rollback()
+
+	current, err := getTask(ctx, tx, req.TaskId, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update task")
+	}
+	if err := checkStartAllowed(current, req.Status, req.Force); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
 	// Handle optional deadline
@@ -202,8 +307,9 @@ func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*p
 		deadlineVal = nil // store NULL when not provided
 	}
 
-	// Update task using the correct columns
-	res, err := s.db.TaskDB.ExecContext(ctx, `
+	// completed_pomodoros is owned by CreditPomodoro; progress follows it against the
+	// new estimate, or the checklist below for tasks without one.
+	_, err = tx.ExecContext(ctx, `
         UPDATE tasks SET
             name = $1,
 			icon = $2,
@@ -211,11 +317,14 @@ func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*p
             priority = $4,
             status = $5,
             total_pomodoros = $6,
-            completed_pomodoros = $7,
-            progress = $8,
-            deadline = $9,
-            updated_at = $10
-        WHERE task_id = $11
+            progress = CASE
+                WHEN $6 > 0 THEN LEAST(100, completed_pomodoros * 100 / $6)
+                ELSE progress
+            END,
+            deadline = $7,
+            updated_at = $8,
+            `+completedAtUpdate("$5", "$8")+`
+        WHERE task_id = $9
     `,
 		req.Name,
 		req.Icon,
@@ -223,8 +332,6 @@ func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*p
 		helper.TaskPriorityDbEnumToString(req.Priority),
 		helper.TaskStatusDbEnumToString(req.Status),
 		req.TotalPomodoros,
-		req.CompletedPomodoros,
-		req.Progress,
 		deadlineVal,
 		now,
 		req.TaskId,
@@ -233,64 +340,47 @@ func (s *Service) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*p
 		log.Printf("Error updating task: %v", err)
 		return nil, status.Error(codes.Internal, "failed to update task")
 	}
-	affected, _ := res.RowsAffected()
-	if affected == 0 {
-		return nil, status.Error(codes.NotFound, "task not found")
+
+	// Re-estimates are kept to measure estimation accuracy.
+	if req.TotalPomodoros != current.TotalPomodoros {
+		if err := recordEstimateChange(ctx, tx, req.TaskId, current.TotalPomodoros, req.TotalPomodoros, now); err != nil {
+			log.Printf("Error recording estimate change: %v", err)
+			return nil, status.Error(codes.Internal, "failed to update task")
+		}
 	}
 
-	// Fetch and return the updated task (convert DB types to protobuf types)
-	var (
-		task                 pb.Task
-		priorityLabel        string
-		statusLabel          string
-		totalPomodoros       int32
-		completedPomodoros   int32
-		progress             int32
-		deadlineNT           sql.NullTime
-		createdAt, updatedAt time.Time
-	)
-	err = s.db.TaskDB.QueryRowContext(ctx, `
-        SELECT
-            task_id, user_id, group_id, icon, name, description,
-            priority, status, total_pomodoros, completed_pomodoros, progress,
-            deadline, created_at, updated_at
-        FROM tasks
-        WHERE task_id = $1
-    `, req.TaskId).Scan(
-		&task.TaskId,
-		&task.UserId,
-		&task.GroupId,
-		&task.Icon,
-		&task.Name,
-		&task.Description,
-		&priorityLabel,
-		&statusLabel,
-		&totalPomodoros,
-		&completedPomodoros,
-		&progress,
-		&deadlineNT,
-		&createdAt,
-		&updatedAt,
-	)
+	// Tasks without a pomodoro estimate derive progress from their checklist.
+	if err := recomputeChecklistProgress(ctx, tx, req.TaskId, now); err != nil {
+		log.Printf("Error recomputing checklist progress: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update task")
+	}
+	if current.Status != req.Status {
+		if err := recomputeGroupCounters(ctx, tx, now, current.GroupId); err != nil {
+			log.Printf("Error recomputing task group counters: %v", err)
+			return nil, status.Error(codes.Internal, "failed to update task")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update task")
+	}
+
+	// Completing an occurrence of a recurring task schedules the next one.
+	if req.Status == pb.TaskStatus_TASK_STATUS_COMPLETED {
+		if err := s.materializeNext(ctx, req.TaskId); err != nil {
+			log.Printf("Error materializing next occurrence of task %s: %v", req.TaskId, err)
+		}
+	}
+
+	// Fetch and return the updated task
+	task, err := getTask(ctx, s.db.TaskDB, req.TaskId, false)
 	if err != nil {
 		log.Printf("Error fetching updated task: %v", err)
 		return nil, status.Error(codes.Internal, "failed to fetch updated task")
 	}
 
-	task.Priority = helper.TaskPriorityDbStringToEnum(priorityLabel)
-	task.Status = helper.TaskStatusDbStringToEnum(statusLabel)
-	task.TotalPomodoros = totalPomodoros
-	task.CompletedPomodoros = completedPomodoros
-	task.Progress = progress
-	if deadlineNT.Valid {
-		task.Deadline = timestamppb.New(deadlineNT.Time)
-	} else {
-		task.Deadline = nil
-	}
-	task.CreatedAt = timestamppb.New(createdAt)
-	task.UpdatedAt = timestamppb.New(updatedAt)
-
-	return &pb.UpdateTaskResponse{Task: &task}, nil
+	return &pb.UpdateTaskResponse{Task: task}, nil
 }
 
 func (s *Service) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
@@ -298,14 +388,34 @@ func (s *Service) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*p
 		return nil, status.Error(codes.InvalidArgument, "task_id is required")
 	}
 
-	res, err := s.db.TaskDB.ExecContext(ctx, "DELETE FROM tasks WHERE task_id = $1", req.TaskId)
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task deletion: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task")
+	}
+	defer tx.Rollback()
+
+	// Deletion moves the task to the trash; it is purged later.
+	now := time.Now()
+	var groupID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE tasks SET deleted_at = $1
+		WHERE task_id = $2 AND deleted_at IS NULL
+		RETURNING group_id`, now, req.TaskId).Scan(&groupID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
 		log.Printf("Error deleting task: %v", err)
 		return nil, status.Error(codes.Internal, "failed to delete task")
 	}
-	affected, _ := res.RowsAffected()
-	if affected == 0 {
-		return nil, status.Error(codes.NotFound, "task not found")
+	if err := recomputeGroupCounters(ctx, tx, now, groupID); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task deletion: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task")
 	}
 
 	return &pb.DeleteTaskResponse{Success: true}, nil
@@ -330,8 +440,8 @@ func (s *Service) CreateTaskGroup(ctx context.Context, req *pb.CreateTaskGroupRe
 	}
 
 	priorityLabel := helper.TaskGroupPriorityDbEnumToString(req.Priority)
-	statusLabel := helper.TaskGroupStatusDbEnumToString(req.Status)
 
+	// Counters and status are derived from the group's tasks; a new group has none.
 	newGroup := &pb.TaskGroup{
 		GroupId:        groupID,
 		UserId:         req.UserId,
@@ -340,20 +450,39 @@ func (s *Service) CreateTaskGroup(ctx context.Context, req *pb.CreateTaskGroupRe
 		Description:    req.Description,
 		Deadline:       req.Deadline,
 		Priority:       req.Priority,
-		Status:         req.Status,
+		Status:         pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE,
 		CompletedTasks: 0,
-		TotalTasks:     req.TotalTasks,
+		TotalTasks:     0,
 		CreatedAt:      timestamppb.New(now),
 		UpdatedAt:      timestamppb.New(now),
 	}
 
-	_, err := s.db.TaskDB.ExecContext(
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task group creation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task group")
+	}
+	defer tx.Rollback()
+
+	// New groups go to the bottom of the user's list.
+	ranks := groupRankList(req.UserId)
+	if err := ranks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task group order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task group")
+	}
+	newGroup.Position, err = ranks.appendRank(ctx, tx)
+	if err != nil {
+		log.Printf("Error ranking new task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task group")
+	}
+
+	_, err = tx.ExecContext(
 		ctx,
 		`INSERT INTO task_groups (
             group_id, user_id, icon, name, description, deadline,
             priority, status, completed_tasks, total_tasks,
-            created_at, updated_at
-        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+            created_at, updated_at, position
+        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
 		newGroup.GroupId,
 		newGroup.UserId,
 		newGroup.Icon,
@@ -361,17 +490,22 @@ func (s *Service) CreateTaskGroup(ctx context.Context, req *pb.CreateTaskGroupRe
 		newGroup.Description,
 		deadlineVal,
 		priorityLabel,
-		statusLabel,
+		helper.TaskGroupStatusDbEnumToString(newGroup.Status),
 		newGroup.CompletedTasks,
 		newGroup.TotalTasks,
 		now,
 		now,
+		newGroup.Position,
 	)
 
 	if err != nil {
 		log.Printf("Error creating task group: %v", err)
 		return nil, status.Error(codes.Internal, "failed to create task group")
 	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create task group")
+	}
 
 	return &pb.CreateTaskGroupResponse{Group: newGroup}, nil
 }
@@ -381,7 +515,13 @@ func (s *Service) GetTaskGroups(ctx context.Context, req *pb.GetTaskGroupsReques
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
 
-	rows, err := s.db.TaskDB.QueryContext(ctx, "SELECT group_id, name, description, created_at, updated_at FROM task_groups WHERE user_id = $1", req.UserId)
+	query := `SELECT ` + taskGroupColumns + ` FROM task_groups WHERE user_id = $1 AND deleted_at IS NULL`
+	if !req.IncludeArchived {
+		query += ` AND archived_at IS NULL`
+	}
+	query += groupRankList(req.UserId).orderBy()
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, query, req.UserId)
 	if err != nil {
 		log.Printf("Error fetching task groups: %v", err)
 		return nil, status.Error(codes.Internal, "failed to fetch task groups")
@@ -390,18 +530,12 @@ func (s *Service) GetTaskGroups(ctx context.Context, req *pb.GetTaskGroupsReques
 
 	var groups []*pb.TaskGroup
 	for rows.Next() {
-		var (
-			group     pb.TaskGroup
-			createdAt time.Time
-			updatedAt time.Time
-		)
-		if err := rows.Scan(&group.GroupId, &group.Name, &group.Description, &createdAt, &updatedAt); err != nil {
+		group, err := scanTaskGroup(rows)
+		if err != nil {
 			log.Printf("Error scanning task group: %v", err)
 			return nil, status.Error(codes.Internal, "failed to scan task group")
 		}
-		group.CreatedAt = timestamppb.New(createdAt)
-		group.UpdatedAt = timestamppb.New(updatedAt)
-		groups = append(groups, &group)
+		groups = append(groups, group)
 	}
 
 	return &pb.GetTaskGroupsResponse{Groups: groups}, nil
@@ -422,8 +556,8 @@ func (s *Service) UpdateTaskGroup(ctx context.Context, req *pb.UpdateTaskGroupRe
 	}
 
 	priorityLabel := helper.TaskGroupPriorityDbEnumToString(req.Priority)
-	statusLabel := helper.TaskGroupStatusDbEnumToString(req.Status)
 
+	// Status and task counters are maintained from the group's tasks and not updated here.
 	res, err := s.db.TaskDB.ExecContext(ctx, `
 		UPDATE task_groups SET
 			icon = $1,
@@ -431,20 +565,14 @@ func (s *Service) UpdateTaskGroup(ctx context.Context, req *pb.UpdateTaskGroupRe
 			description = $3,
 			deadline = $4,
 			priority = $5,
-			status = $6,
-			completed_tasks = $7,
-			total_tasks = $8,
-			updated_at = $9
-		WHERE group_id = $10
+			updated_at = $6
+		WHERE group_id = $7 AND deleted_at IS NULL
 	`,
 		req.Icon,
 		req.Name,
 		req.Description,
 		deadlineVal,
 		priorityLabel,
-		statusLabel,
-		req.CompletedTasks,
-		req.TotalTasks,
 		now,
 		req.GroupId,
 	)
@@ -460,12 +588,28 @@ func (s *Service) UpdateTaskGroup(ctx context.Context, req *pb.UpdateTaskGroupRe
 	return &pb.UpdateTaskGroupResponse{Success: true}, nil
 }
 
+// DeleteTaskGroup moves a group and its tasks to the trash. The tasks share the group's
+// deleted_at, which is how RestoreTaskGroup tells them from tasks trashed earlier.
 func (s *Service) DeleteTaskGroup(ctx context.Context, req *pb.DeleteTaskGroupRequest) (*pb.DeleteTaskGroupResponse, error) {
 	if req.GroupId == "" {
 		return nil, status.Error(codes.InvalidArgument, "group_id is required")
 	}
 
-	res, err := s.db.TaskDB.ExecContext(ctx, "DELETE FROM task_groups WHERE group_id = $1", req.GroupId)
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task group deletion: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task group")
+	}
+	defer tx.Rollback()
+
+	if err := lockGroupTasks(ctx, tx, req.GroupId); err != nil {
+		log.Printf("Error locking tasks of task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task group")
+	}
+
+	now := time.Now()
+	res, err := tx.ExecContext(ctx,
+		"UPDATE task_groups SET deleted_at = $1 WHERE group_id = $2 AND deleted_at IS NULL", now, req.GroupId)
 	if err != nil {
 		log.Printf("Error deleting task group: %v", err)
 		return nil, status.Error(codes.Internal, "failed to delete task group")
@@ -475,5 +619,15 @@ func (s *Service) DeleteTaskGroup(ctx context.Context, req *pb.DeleteTaskGroupRe
 		return nil, status.Error(codes.NotFound, "task group not found")
 	}
 
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE tasks SET deleted_at = $1 WHERE group_id = $2 AND deleted_at IS NULL", now, req.GroupId); err != nil {
+		log.Printf("Error deleting tasks of task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task group")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task group deletion: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete task group")
+	}
+
 	return &pb.DeleteTaskGroupResponse{Success: true}, nil
 }
@@ -11,6 +11,10 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,6 +23,7 @@ import (
 	"github.com/latrung124/Totodoro-Backend/internal/database"
 	"github.com/latrung124/Totodoro-Backend/internal/helper"
 	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -154,16 +159,17 @@ func TestCreateTaskGroup(t *testing.T) {
 		t.Errorf("Expected description %q, got %q", description, resp.Group.Description)
 	}
 
-	if resp.Group.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_UNSPECIFIED {
-		t.Errorf("Expected default status UNSPECIFIED, got %v", resp.Group.Status)
+	// Status and counters are derived from the group's tasks, not taken from the request.
+	if resp.Group.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE {
+		t.Errorf("Expected derived status IDLE, got %v", resp.Group.Status)
 	}
 
 	if resp.Group.CompletedTasks != 0 {
 		t.Errorf("Expected completed_tasks=0, got %d", resp.Group.CompletedTasks)
 	}
 
-	if resp.Group.TotalTasks != totalTasks {
-		t.Errorf("Expected total_tasks=%d, got %d", totalTasks, resp.Group.TotalTasks)
+	if resp.Group.TotalTasks != 0 {
+		t.Errorf("Expected total_tasks=0, got %d", resp.Group.TotalTasks)
 	}
 
 	if resp.Group.CreatedAt.AsTime().After(time.Now().Add(1*time.Second)) ||
@@ -207,8 +213,8 @@ func TestCreateTaskGroup(t *testing.T) {
 		t.Errorf("Persisted completed_tasks expected 0, got %d", gotCompleted)
 	}
 
-	if gotTotal != totalTasks {
-		t.Errorf("Persisted total_tasks expected %d, got %d", totalTasks, gotTotal)
+	if gotTotal != 0 {
+		t.Errorf("Persisted total_tasks expected 0, got %d", gotTotal)
 	}
 
 	if resp.Group.Deadline == nil || !timesClose(resp.Group.Deadline.AsTime().UTC(), deadline.UTC(), time.Second) {
@@ -366,14 +372,14 @@ func TestUpdateTaskGroup(t *testing.T) {
 		t.Errorf("Persisted priority expected %s, got %s", string(priorityLabel), gotPriority)
 	}
 
-	statusLabel := helper.TaskGroupStatusDbEnumToString(newStatus)
+	// The group has no tasks, so client-supplied status and counters are ignored.
+	statusLabel := helper.TaskGroupStatusDbEnumToString(pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE)
 	if gotStatus != string(statusLabel) {
 		t.Errorf("Persisted status expected %s, got %s", string(statusLabel), gotStatus)
 	}
 
-	if gotCompleted != completedTasks || gotTotal != totalTasks {
-		t.Errorf("Persisted completed/total expected %d/%d, got %d/%d",
-			completedTasks, totalTasks, gotCompleted, gotTotal)
+	if gotCompleted != 0 || gotTotal != 0 {
+		t.Errorf("Persisted completed/total expected 0/0, got %d/%d", gotCompleted, gotTotal)
 	}
 }
 
@@ -406,14 +412,15 @@ func TestDeleteTaskGroup(t *testing.T) {
 		t.Errorf("Expected success true, got false")
 	}
 
-	var count int
-	err = connections.TaskDB.QueryRow("SELECT COUNT(*) FROM task_groups WHERE group_id = $1", groupId).Scan(&count)
+	// Deleted groups move to the trash rather than being removed.
+	var trashed bool
+	err = connections.TaskDB.QueryRow("SELECT deleted_at IS NOT NULL FROM task_groups WHERE group_id = $1", groupId).Scan(&trashed)
 	if err != nil {
 		t.Fatalf("Failed to verify deletion: %v", err)
 	}
 
-	if count != 0 {
-		t.Errorf("Expected 0 rows for group_id %s, found %d", groupId, count)
+	if !trashed {
+		t.Errorf("Expected group_id %s to be in the trash", groupId)
 	}
 }
 
@@ -636,14 +643,14 @@ func TestUpdateTask(t *testing.T) {
 	status := pb.TaskStatus_TASK_STATUS_IDLE
 	totalPomodoros := int32(3)
 	completedPomodoros := int32(1)
-	progress := int32(60)
+	progress := int32(33)
 	deadline := time.Now().Add(24 * time.Hour)
 
 	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
 	defer RemoveTaskGroup(connections, groupId)
 
 	seedTask(t, connections.TaskDB, taskId, userId, groupId, icon, name, description,
-		priority, status, totalPomodoros, 0, 0, &deadline)
+		priority, status, totalPomodoros, completedPomodoros, progress, &deadline)
 	defer RemoveTask(connections, taskId)
 
 	newName := "Updated Task Name"
@@ -659,8 +666,8 @@ func TestUpdateTask(t *testing.T) {
 		Priority:           newPriority,
 		Status:             newStatus,
 		TotalPomodoros:     totalPomodoros,
-		CompletedPomodoros: completedPomodoros,
-		Progress:           progress,
+		CompletedPomodoros: 0, // Ignored: the server keeps the credited count
+		Progress:           100,
 		Deadline:           timestamppb.New(deadline),
 	}
 
@@ -709,3 +716,1636 @@ func TestUpdateTask(t *testing.T) {
 		t.Errorf("Expected deadline ~%v, got %v", deadline, resp.Task.Deadline.AsTime())
 	}
 }
+
+func TestSubtasksDriveProgress(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	// No pomodoro estimate: progress follows the checklist.
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+		UserId:  userId,
+		GroupId: groupId,
+		Name:    "Checklist task",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask without estimate failed: %v", err)
+	}
+	taskId := created.Task.TaskId
+	defer RemoveTask(connections, taskId)
+
+	first, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: taskId, Title: "Outline"})
+	if err != nil {
+		t.Fatalf("CreateSubtask failed: %v", err)
+	}
+	second, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: taskId, Title: "Draft"})
+	if err != nil {
+		t.Fatalf("CreateSubtask failed: %v", err)
+	}
+	if second.Subtask.Position <= first.Subtask.Position {
+		t.Errorf("Expected appended subtask after %d, got position %d", first.Subtask.Position, second.Subtask.Position)
+	}
+
+	updated, err := service.UpdateSubtask(ctx, &pb.UpdateSubtaskRequest{
+		TaskId:    taskId,
+		SubtaskId: first.Subtask.SubtaskId,
+		Title:     first.Subtask.Title,
+		Done:      true,
+		Position:  first.Subtask.Position,
+	})
+	if err != nil {
+		t.Fatalf("UpdateSubtask failed: %v", err)
+	}
+	if updated.Task.Progress != 50 || updated.Task.CompletedSubtasks != 1 || updated.Task.TotalSubtasks != 2 {
+		t.Errorf("Expected 50%% progress with 1/2 subtasks, got %d%% with %d/%d",
+			updated.Task.Progress, updated.Task.CompletedSubtasks, updated.Task.TotalSubtasks)
+	}
+
+	deleted, err := service.DeleteSubtask(ctx, &pb.DeleteSubtaskRequest{TaskId: taskId, SubtaskId: second.Subtask.SubtaskId})
+	if err != nil {
+		t.Fatalf("DeleteSubtask failed: %v", err)
+	}
+	if deleted.Task.Progress != 100 {
+		t.Errorf("Expected 100%% progress after removing the open subtask, got %d", deleted.Task.Progress)
+	}
+
+	list, err := service.GetSubtasks(ctx, &pb.GetSubtasksRequest{TaskId: taskId})
+	if err != nil {
+		t.Fatalf("GetSubtasks failed: %v", err)
+	}
+	if len(list.Subtasks) != 1 || list.Subtasks[0].SubtaskId != first.Subtask.SubtaskId {
+		t.Errorf("Expected only the first subtask to remain, got %v", list.Subtasks)
+	}
+
+	emptied, err := service.DeleteSubtask(ctx, &pb.DeleteSubtaskRequest{TaskId: taskId, SubtaskId: first.Subtask.SubtaskId})
+	if err != nil {
+		t.Fatalf("DeleteSubtask failed: %v", err)
+	}
+	if emptied.Task.Progress != 0 || emptied.Task.TotalSubtasks != 0 {
+		t.Errorf("Expected 0%% progress once the last subtask is gone, got %d%% with %d subtasks",
+			emptied.Task.Progress, emptied.Task.TotalSubtasks)
+	}
+
+	if _, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: uuid.NewString(), Title: "Orphan"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a missing task, got %v", err)
+	}
+
+	// The checklist of a task in the trash cannot be changed.
+	trashed, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: taskId, Title: "Review"})
+	if err != nil {
+		t.Fatalf("CreateSubtask failed: %v", err)
+	}
+	if _, err := service.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: taskId}); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if _, err := service.UpdateSubtask(ctx, &pb.UpdateSubtaskRequest{
+		TaskId:    taskId,
+		SubtaskId: trashed.Subtask.SubtaskId,
+		Title:     trashed.Subtask.Title,
+		Done:      true,
+	}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound updating a subtask of a trashed task, got %v", err)
+	}
+	if _, err := service.DeleteSubtask(ctx, &pb.DeleteSubtaskRequest{TaskId: taskId, SubtaskId: trashed.Subtask.SubtaskId}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound deleting a subtask of a trashed task, got %v", err)
+	}
+}
+
+func TestTagsFilterTasks(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	deepWork, err := service.CreateTag(ctx, &pb.CreateTagRequest{UserId: userId, Name: "deep-work", Color: "#3366ff"})
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	defer service.DeleteTag(ctx, &pb.DeleteTagRequest{TagId: deepWork.Tag.TagId})
+	clientX, err := service.CreateTag(ctx, &pb.CreateTagRequest{UserId: userId, Name: "client-x"})
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	defer service.DeleteTag(ctx, &pb.DeleteTagRequest{TagId: clientX.Tag.TagId})
+
+	if _, err := service.CreateTag(ctx, &pb.CreateTagRequest{UserId: userId, Name: "Deep-Work"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists for a case-insensitive duplicate, got %v", err)
+	}
+
+	bothId := uuid.NewString()
+	oneId := uuid.NewString()
+	seedTask(t, connections.TaskDB, bothId, userId, groupId, "", "Both tags", "",
+		pb.TaskPriority_TASK_PRIORITY_MEDIUM, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+	defer RemoveTask(connections, bothId)
+	seedTask(t, connections.TaskDB, oneId, userId, groupId, "", "One tag", "",
+		pb.TaskPriority_TASK_PRIORITY_MEDIUM, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+	defer RemoveTask(connections, oneId)
+
+	tagged, err := service.SetTaskTags(ctx, &pb.SetTaskTagsRequest{TaskId: bothId, TagIds: []string{deepWork.Tag.TagId, clientX.Tag.TagId}})
+	if err != nil {
+		t.Fatalf("SetTaskTags failed: %v", err)
+	}
+	if len(tagged.Task.TagIds) != 2 {
+		t.Errorf("Expected 2 tags on task, got %v", tagged.Task.TagIds)
+	}
+	if _, err := service.SetTaskTags(ctx, &pb.SetTaskTagsRequest{TaskId: oneId, TagIds: []string{deepWork.Tag.TagId}}); err != nil {
+		t.Fatalf("SetTaskTags failed: %v", err)
+	}
+	if _, err := service.SetTaskTags(ctx, &pb.SetTaskTagsRequest{TaskId: oneId, TagIds: []string{uuid.NewString()}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for another user's tag, got %v", err)
+	}
+
+	anyResp, err := service.GetTasks(ctx, &pb.GetTasksRequest{
+		UserId:   userId,
+		TagIds:   []string{deepWork.Tag.TagId, clientX.Tag.TagId},
+		TagMatch: pb.TagMatch_TAG_MATCH_ANY,
+	})
+	if err != nil {
+		t.Fatalf("GetTasks (any) failed: %v", err)
+	}
+	if len(anyResp.Tasks) != 2 {
+		t.Errorf("Expected 2 tasks matching any tag, got %d", len(anyResp.Tasks))
+	}
+
+	allResp, err := service.GetTasks(ctx, &pb.GetTasksRequest{
+		UserId:   userId,
+		TagIds:   []string{deepWork.Tag.TagId, clientX.Tag.TagId},
+		TagMatch: pb.TagMatch_TAG_MATCH_ALL,
+	})
+	if err != nil {
+		t.Fatalf("GetTasks (all) failed: %v", err)
+	}
+	if len(allResp.Tasks) != 1 || allResp.Tasks[0].TaskId != bothId {
+		t.Errorf("Expected only the task with both tags, got %v", allResp.Tasks)
+	}
+}
+
+func TestRecurringTaskMaterializesNext(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	if _, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+		UserId:         userId,
+		GroupId:        groupId,
+		Name:           "No deadline",
+		RecurrenceRule: "FREQ=DAILY",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a recurring task without deadline, got %v", err)
+	}
+
+	deadline := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+		UserId:         userId,
+		GroupId:        groupId,
+		Name:           "Weekly review",
+		TotalPomodoros: 2,
+		Deadline:       timestamppb.New(deadline),
+		RecurrenceRule: "FREQ=WEEKLY;COUNT=2",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	first := created.Task
+	defer connections.TaskDB.Exec("DELETE FROM tasks WHERE series_id = $1", first.TaskId)
+	if first.SeriesId != first.TaskId || first.Occurrence != 1 {
+		t.Errorf("Expected task to start its own series, got series %q occurrence %d", first.SeriesId, first.Occurrence)
+	}
+
+	if _, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: first.TaskId, Title: "Inbox zero"}); err != nil {
+		t.Fatalf("CreateSubtask failed: %v", err)
+	}
+
+	complete := func(task *pb.Task) {
+		t.Helper()
+		if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{
+			TaskId:         task.TaskId,
+			Name:           task.Name,
+			Priority:       task.Priority,
+			Status:         pb.TaskStatus_TASK_STATUS_COMPLETED,
+			TotalPomodoros: task.TotalPomodoros,
+			Deadline:       task.Deadline,
+		}); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+	}
+	complete(first)
+	// Completing twice must not create a second copy.
+	complete(first)
+
+	resp, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	var next *pb.Task
+	for _, task := range resp.Tasks {
+		if task.Occurrence == 2 {
+			if next != nil {
+				t.Fatalf("Expected one second occurrence, got several")
+			}
+			next = task
+		}
+	}
+	if next == nil {
+		t.Fatalf("Expected the second occurrence to be created, got %v", resp.Tasks)
+	}
+	if next.SeriesId != first.TaskId || next.Status != pb.TaskStatus_TASK_STATUS_IDLE || next.TotalSubtasks != 1 {
+		t.Errorf("Unexpected second occurrence %+v", next)
+	}
+	if want := deadline.AddDate(0, 0, 7); !next.Deadline.AsTime().Equal(want) {
+		t.Errorf("Expected next deadline %v, got %v", want, next.Deadline.AsTime())
+	}
+
+	// COUNT=2 ends the series.
+	complete(next)
+	resp, err = service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(resp.Tasks) != 2 {
+		t.Errorf("Expected the series to end after 2 occurrences, got %d tasks", len(resp.Tasks))
+	}
+	var ended bool
+	if err := connections.TaskDB.QueryRow(
+		"SELECT ended_at IS NOT NULL FROM task_series WHERE series_id = $1", first.TaskId,
+	).Scan(&ended); err != nil || !ended {
+		t.Errorf("Expected the series to be marked ended, got %v (%v)", ended, err)
+	}
+	defer connections.TaskDB.Exec("DELETE FROM task_series WHERE series_id = $1", first.TaskId)
+
+	// A purged occurrence is not recreated by the scheduler, even once the series is live again.
+	if _, err := connections.TaskDB.Exec("UPDATE task_series SET ended_at = NULL WHERE series_id = $1", first.TaskId); err != nil {
+		t.Fatalf("Failed to reopen series: %v", err)
+	}
+	RemoveTask(connections, next.TaskId)
+	service.advanceRecurrences(ctx)
+	resp, err = service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(resp.Tasks) != 1 {
+		t.Errorf("Expected the purged occurrence to stay gone, got %d tasks", len(resp.Tasks))
+	}
+}
+
+func TestRecurrenceSetAgainStartsNewSeries(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+	defer connections.TaskDB.Exec("DELETE FROM tasks WHERE group_id = $1", groupId)
+
+	deadline := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+		UserId:         userId,
+		GroupId:        groupId,
+		Name:           "Standup",
+		Deadline:       timestamppb.New(deadline),
+		RecurrenceRule: "FREQ=DAILY",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	head := created.Task
+	defer connections.TaskDB.Exec("DELETE FROM task_series WHERE series_id = $1", head.TaskId)
+
+	complete := func(task *pb.Task) {
+		t.Helper()
+		if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{
+			TaskId:   task.TaskId,
+			Name:     task.Name,
+			Status:   pb.TaskStatus_TASK_STATUS_COMPLETED,
+			Deadline: task.Deadline,
+		}); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+	}
+	// The head has spawned occurrence 2 before it gets a new rule.
+	complete(head)
+
+	reruled, err := service.SetTaskRecurrence(ctx, &pb.SetTaskRecurrenceRequest{TaskId: head.TaskId, RecurrenceRule: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("SetTaskRecurrence failed: %v", err)
+	}
+	task := reruled.Task
+	defer connections.TaskDB.Exec("DELETE FROM task_series WHERE series_id = $1", task.SeriesId)
+	if task.SeriesId == head.TaskId || task.Occurrence != 1 {
+		t.Fatalf("Expected the head to start a new series, got series %q occurrence %d", task.SeriesId, task.Occurrence)
+	}
+
+	// Completing it again schedules from the new rule, in the new series.
+	complete(task)
+	resp, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	var daily, weekly *pb.Task
+	for _, next := range resp.Tasks {
+		if next.Occurrence != 2 {
+			continue
+		}
+		switch next.SeriesId {
+		case head.TaskId:
+			daily = next
+		case task.SeriesId:
+			weekly = next
+		}
+	}
+	if daily == nil || !daily.Deadline.AsTime().Equal(deadline.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the old series to keep its daily occurrence, got %v", daily)
+	}
+	if weekly == nil || !weekly.Deadline.AsTime().Equal(deadline.AddDate(0, 0, 7)) {
+		t.Errorf("Expected a weekly occurrence in the new series, got %v", weekly)
+	}
+}
+
+func TestTaskDependenciesBlockStart(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	designId := uuid.NewString()
+	buildId := uuid.NewString()
+	shipId := uuid.NewString()
+	for id, name := range map[string]string{designId: "Design", buildId: "Build", shipId: "Ship"} {
+		seedTask(t, connections.TaskDB, id, userId, groupId, "", name, "",
+			pb.TaskPriority_TASK_PRIORITY_MEDIUM, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+		defer RemoveTask(connections, id)
+	}
+
+	added, err := service.AddTaskDependency(ctx, &pb.AddTaskDependencyRequest{TaskId: buildId, DependsOnId: designId})
+	if err != nil {
+		t.Fatalf("AddTaskDependency failed: %v", err)
+	}
+	if !added.Task.Blocked || len(added.Task.DependsOn) != 1 || added.Task.DependsOn[0] != designId {
+		t.Errorf("Expected build to be blocked by design, got %+v", added.Task)
+	}
+	if _, err := service.AddTaskDependency(ctx, &pb.AddTaskDependencyRequest{TaskId: shipId, DependsOnId: buildId}); err != nil {
+		t.Fatalf("AddTaskDependency failed: %v", err)
+	}
+
+	// design -> ship would close design <- build <- ship.
+	if _, err := service.AddTaskDependency(ctx, &pb.AddTaskDependencyRequest{TaskId: designId, DependsOnId: shipId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a cycle, got %v", err)
+	}
+
+	start := &pb.UpdateTaskRequest{
+		TaskId:         buildId,
+		Name:           "Build",
+		Priority:       pb.TaskPriority_TASK_PRIORITY_MEDIUM,
+		Status:         pb.TaskStatus_TASK_STATUS_IN_PROGRESS,
+		TotalPomodoros: 1,
+	}
+	if _, err := service.UpdateTask(ctx, start); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition when starting a blocked task, got %v", err)
+	}
+
+	if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{
+		TaskId:         designId,
+		Name:           "Design",
+		Priority:       pb.TaskPriority_TASK_PRIORITY_MEDIUM,
+		Status:         pb.TaskStatus_TASK_STATUS_COMPLETED,
+		TotalPomodoros: 1,
+	}); err != nil {
+		t.Fatalf("UpdateTask (complete design) failed: %v", err)
+	}
+	started, err := service.UpdateTask(ctx, start)
+	if err != nil {
+		t.Fatalf("UpdateTask (start build) failed: %v", err)
+	}
+	if started.Task.Blocked || started.Task.Status != pb.TaskStatus_TASK_STATUS_IN_PROGRESS {
+		t.Errorf("Expected build to be unblocked and in progress, got %+v", started.Task)
+	}
+
+	if _, err := service.RemoveTaskDependency(ctx, &pb.RemoveTaskDependencyRequest{TaskId: shipId, DependsOnId: buildId}); err != nil {
+		t.Fatalf("RemoveTaskDependency failed: %v", err)
+	}
+	if _, err := service.RemoveTaskDependency(ctx, &pb.RemoveTaskDependencyRequest{TaskId: shipId, DependsOnId: buildId}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a removed dependency, got %v", err)
+	}
+}
+
+func TestMoveTaskKeepsOrder(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	var ids []string
+	for _, name := range []string{"First", "Second", "Third"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: name})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		ids = append(ids, created.Task.TaskId)
+	}
+
+	order := func() []string {
+		t.Helper()
+		resp, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+		if err != nil {
+			t.Fatalf("GetTasks failed: %v", err)
+		}
+		var got []string
+		for _, task := range resp.Tasks {
+			got = append(got, task.TaskId)
+		}
+		return got
+	}
+	if got := order(); !slices.Equal(got, ids) {
+		t.Fatalf("Expected creation order %v, got %v", ids, got)
+	}
+
+	// Third to the top, then First after Second.
+	if _, err := service.MoveTask(ctx, &pb.MoveTaskRequest{TaskId: ids[2]}); err != nil {
+		t.Fatalf("MoveTask to top failed: %v", err)
+	}
+	if _, err := service.MoveTask(ctx, &pb.MoveTaskRequest{TaskId: ids[0], AfterTaskId: ids[1]}); err != nil {
+		t.Fatalf("MoveTask after failed: %v", err)
+	}
+	if got, want := order(), []string{ids[2], ids[1], ids[0]}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+
+	if _, err := service.MoveTask(ctx, &pb.MoveTaskRequest{TaskId: ids[0], AfterTaskId: uuid.NewString()}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown neighbor, got %v", err)
+	}
+
+	// Rows sharing a rank are renumbered in the order they are listed in.
+	if _, err := connections.TaskDB.Exec("UPDATE tasks SET position = 'i' WHERE group_id = $1", groupId); err != nil {
+		t.Fatalf("Failed to reset positions: %v", err)
+	}
+	listed := order()
+	if _, err := service.MoveTask(ctx, &pb.MoveTaskRequest{TaskId: listed[2], AfterTaskId: listed[0]}); err != nil {
+		t.Fatalf("MoveTask among equal ranks failed: %v", err)
+	}
+	if got, want := order(), []string{listed[0], listed[2], listed[1]}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %v after renumbering, got %v", want, got)
+	}
+}
+
+func TestTaskGroupCountersFollowTasks(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	group := func() *pb.TaskGroup {
+		t.Helper()
+		resp, err := service.GetTaskGroups(ctx, &pb.GetTaskGroupsRequest{UserId: userId})
+		if err != nil {
+			t.Fatalf("GetTaskGroups failed: %v", err)
+		}
+		if len(resp.Groups) != 1 {
+			t.Fatalf("Expected 1 group, got %d", len(resp.Groups))
+		}
+		return resp.Groups[0]
+	}
+
+	var tasks []*pb.Task
+	for _, name := range []string{"One", "Two"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: name, TotalPomodoros: 1})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		tasks = append(tasks, created.Task)
+	}
+	if g := group(); g.TotalTasks != 2 || g.CompletedTasks != 0 || g.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE {
+		t.Errorf("Expected 0/2 idle, got %d/%d %v", g.CompletedTasks, g.TotalTasks, g.Status)
+	}
+
+	complete := &pb.UpdateTaskRequest{
+		TaskId:         tasks[0].TaskId,
+		Name:           tasks[0].Name,
+		Status:         pb.TaskStatus_TASK_STATUS_COMPLETED,
+		TotalPomodoros: 1,
+	}
+	if _, err := service.UpdateTask(ctx, complete); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if g := group(); g.CompletedTasks != 1 || g.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_IN_PROGRESS {
+		t.Errorf("Expected 1/2 in progress, got %d/%d %v", g.CompletedTasks, g.TotalTasks, g.Status)
+	}
+
+	// Crediting the last pomodoro completes the second task, and with it the group.
+	if err := service.CreditPomodoro(ctx, uuid.NewString(), tasks[1].TaskId, 25*time.Minute); err != nil {
+		t.Fatalf("CreditPomodoro failed: %v", err)
+	}
+	if g := group(); g.CompletedTasks != 2 || g.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_COMPLETED {
+		t.Errorf("Expected 2/2 completed, got %d/%d %v", g.CompletedTasks, g.TotalTasks, g.Status)
+	}
+
+	if _, err := service.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: tasks[1].TaskId}); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if g := group(); g.TotalTasks != 1 || g.CompletedTasks != 1 || g.Status != pb.TaskGroupStatus_TASK_GROUP_STATUS_COMPLETED {
+		t.Errorf("Expected 1/1 completed after delete, got %d/%d %v", g.CompletedTasks, g.TotalTasks, g.Status)
+	}
+}
+
+func TestUpdateTaskKeepsCreditedPomodoros(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: "Credited", TotalPomodoros: 4})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	taskId := created.Task.TaskId
+	defer RemoveTask(connections, taskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", taskId)
+
+	if err := service.CreditPomodoro(ctx, uuid.NewString(), taskId, 25*time.Minute); err != nil {
+		t.Fatalf("CreditPomodoro failed: %v", err)
+	}
+
+	// A client holding a stale copy of the task must not reset the credited count.
+	resp, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{
+		TaskId:             taskId,
+		Name:               "Credited (renamed)",
+		TotalPomodoros:     2,
+		CompletedPomodoros: 0,
+		Progress:           0,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if resp.Task.CompletedPomodoros != 1 {
+		t.Errorf("Expected the credited pomodoro to survive, got %d", resp.Task.CompletedPomodoros)
+	}
+	if resp.Task.Progress != 50 {
+		t.Errorf("Expected progress against the new estimate of 2 to be 50, got %d", resp.Task.Progress)
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	titleId := uuid.NewString()
+	bodyId := uuid.NewString()
+	otherId := uuid.NewString()
+	seedTask(t, connections.TaskDB, titleId, userId, groupId, "", "Quarterly report", "Numbers for the board",
+		pb.TaskPriority_TASK_PRIORITY_HIGH, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+	defer RemoveTask(connections, titleId)
+	seedTask(t, connections.TaskDB, bodyId, userId, groupId, "", "Board meeting", "Present the quarterly numbers",
+		pb.TaskPriority_TASK_PRIORITY_LOW, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+	defer RemoveTask(connections, bodyId)
+	seedTask(t, connections.TaskDB, otherId, userId, groupId, "", "Groceries", "Milk and eggs",
+		pb.TaskPriority_TASK_PRIORITY_LOW, pb.TaskStatus_TASK_STATUS_IDLE, 1, 0, 0, nil)
+	defer RemoveTask(connections, otherId)
+
+	resp, err := service.SearchTasks(ctx, &pb.SearchTasksRequest{UserId: userId, Query: "quart"})
+	if err != nil {
+		t.Fatalf("SearchTasks failed: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected 2 prefix matches, got %d", len(resp.Results))
+	}
+	if resp.Results[0].Task.TaskId != titleId {
+		t.Errorf("Expected the name match to rank first, got %q", resp.Results[0].Task.Name)
+	}
+	if !strings.Contains(resp.Results[0].NameHighlight, "<mark>Quarterly</mark>") {
+		t.Errorf("Expected highlighted name, got %q", resp.Results[0].NameHighlight)
+	}
+
+	page, err := service.SearchTasks(ctx, &pb.SearchTasksRequest{UserId: userId, Query: "quart", PageSize: 1})
+	if err != nil {
+		t.Fatalf("SearchTasks (page 1) failed: %v", err)
+	}
+	if len(page.Results) != 1 || page.NextPageToken == "" {
+		t.Fatalf("Expected 1 result and a next page token, got %d results, token %q", len(page.Results), page.NextPageToken)
+	}
+	page, err = service.SearchTasks(ctx, &pb.SearchTasksRequest{UserId: userId, Query: "quart", PageSize: 1, PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatalf("SearchTasks (page 2) failed: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].Task.TaskId != bodyId || page.NextPageToken != "" {
+		t.Errorf("Expected the description match on the last page, got %v", page.Results)
+	}
+
+	filtered, err := service.SearchTasks(ctx, &pb.SearchTasksRequest{
+		UserId:     userId,
+		Query:      "board",
+		Priorities: []pb.TaskPriority{pb.TaskPriority_TASK_PRIORITY_LOW},
+	})
+	if err != nil {
+		t.Fatalf("SearchTasks (filtered) failed: %v", err)
+	}
+	if len(filtered.Results) != 1 || filtered.Results[0].Task.TaskId != bodyId {
+		t.Errorf("Expected only the low-priority match, got %v", filtered.Results)
+	}
+
+	if _, err := service.SearchTasks(ctx, &pb.SearchTasksRequest{UserId: userId, Query: "?!"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a query without words, got %v", err)
+	}
+}
+
+func TestGetTasksFiltersAndPages(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	otherGroupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+	seedTaskGroup(t, connections.TaskDB, otherGroupId, userId, "Other Group", "Another test group")
+	defer RemoveTaskGroup(connections, otherGroupId)
+
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	var ids []string
+	for i, priority := range []pb.TaskPriority{
+		pb.TaskPriority_TASK_PRIORITY_HIGH,
+		pb.TaskPriority_TASK_PRIORITY_LOW,
+		pb.TaskPriority_TASK_PRIORITY_HIGH,
+	} {
+		deadline := timestamppb.New(base.Add(time.Duration(3-i) * time.Hour))
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+			UserId:   userId,
+			GroupId:  groupId,
+			Name:     "Task " + strconv.Itoa(i),
+			Priority: priority,
+			Deadline: deadline,
+		})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		ids = append(ids, created.Task.TaskId)
+	}
+	other, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: otherGroupId, Name: "Elsewhere"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	defer RemoveTask(connections, other.Task.TaskId)
+
+	byGroup, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId, GroupId: groupId})
+	if err != nil {
+		t.Fatalf("GetTasks by group failed: %v", err)
+	}
+	if len(byGroup.Tasks) != 3 {
+		t.Errorf("Expected 3 tasks in the group, got %d", len(byGroup.Tasks))
+	}
+
+	high, err := service.GetTasks(ctx, &pb.GetTasksRequest{
+		UserId:     userId,
+		Priorities: []pb.TaskPriority{pb.TaskPriority_TASK_PRIORITY_HIGH},
+	})
+	if err != nil {
+		t.Fatalf("GetTasks by priority failed: %v", err)
+	}
+	if len(high.Tasks) != 2 {
+		t.Errorf("Expected 2 high-priority tasks, got %d", len(high.Tasks))
+	}
+
+	// By deadline, one task per page: the last created task is due first.
+	var (
+		paged      []string
+		firstToken string
+	)
+	token := ""
+	for {
+		resp, err := service.GetTasks(ctx, &pb.GetTasksRequest{
+			UserId:    userId,
+			GroupId:   groupId,
+			SortBy:    pb.TaskSortKey_TASK_SORT_KEY_DEADLINE,
+			PageSize:  1,
+			PageToken: token,
+		})
+		if err != nil {
+			t.Fatalf("GetTasks page failed: %v", err)
+		}
+		for _, task := range resp.Tasks {
+			paged = append(paged, task.TaskId)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+		if firstToken == "" {
+			firstToken = token
+		}
+	}
+	if want := []string{ids[2], ids[1], ids[0]}; !slices.Equal(paged, want) {
+		t.Errorf("Expected deadline order %v, got %v", want, paged)
+	}
+
+	// A token only continues the ordering it was issued for.
+	_, err = service.GetTasks(ctx, &pb.GetTasksRequest{
+		UserId:    userId,
+		SortBy:    pb.TaskSortKey_TASK_SORT_KEY_DEADLINE,
+		Order:     pb.SortOrder_SORT_ORDER_DESC,
+		PageToken: firstToken,
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a token from another ordering, got %v", err)
+	}
+
+	if _, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId, PageToken: "not-a-token"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad page token, got %v", err)
+	}
+
+	// Malformed filters are reported as such, not as a bad page token.
+	for _, tc := range []struct {
+		req  *pb.GetTasksRequest
+		want string
+	}{
+		{&pb.GetTasksRequest{UserId: userId, TagIds: []string{"not-a-uuid"}}, "tag_ids must be valid UUIDs"},
+		{&pb.GetTasksRequest{UserId: userId, GroupId: "not-a-uuid"}, "group_id must be a valid UUID"},
+		{&pb.GetTasksRequest{GroupId: groupId}, "user_id is required"},
+	} {
+		_, err := service.GetTasks(ctx, tc.req)
+		if status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != tc.want {
+			t.Errorf("Expected InvalidArgument %q, got %v", tc.want, err)
+		}
+	}
+}
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	var tasks []*pb.Task
+	for _, name := range []string{"Keep", "Trash"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: name, TotalPomodoros: 1})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		tasks = append(tasks, created.Task)
+	}
+
+	// A deleted task leaves the lists and shows up in the trash.
+	if _, err := service.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: tasks[1].TaskId}); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	listed, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(listed.Tasks) != 1 || listed.Tasks[0].TaskId != tasks[0].TaskId {
+		t.Errorf("Expected only the kept task to be listed, got %v", listed.Tasks)
+	}
+	trash, err := service.ListTrash(ctx, &pb.ListTrashRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(trash.Tasks) != 1 || trash.Tasks[0].TaskId != tasks[1].TaskId || trash.Tasks[0].DeletedAt == nil {
+		t.Errorf("Expected the deleted task in the trash, got %v", trash.Tasks)
+	}
+
+	restored, err := service.RestoreTask(ctx, &pb.RestoreTaskRequest{TaskId: tasks[1].TaskId})
+	if err != nil {
+		t.Fatalf("RestoreTask failed: %v", err)
+	}
+	if restored.Task.DeletedAt != nil {
+		t.Errorf("Expected the restored task to have no deleted_at")
+	}
+
+	// Deleting the group trashes its tasks; restoring it brings them back.
+	if _, err := service.DeleteTaskGroup(ctx, &pb.DeleteTaskGroupRequest{GroupId: groupId}); err != nil {
+		t.Fatalf("DeleteTaskGroup failed: %v", err)
+	}
+	trash, err = service.ListTrash(ctx, &pb.ListTrashRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(trash.Groups) != 1 || len(trash.Tasks) != 0 {
+		t.Errorf("Expected 1 group and no loose tasks in the trash, got %d groups and %d tasks", len(trash.Groups), len(trash.Tasks))
+	}
+	if _, err := service.RestoreTask(ctx, &pb.RestoreTaskRequest{TaskId: tasks[0].TaskId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition restoring a task of a trashed group, got %v", err)
+	}
+	if _, err := service.RestoreTaskGroup(ctx, &pb.RestoreTaskGroupRequest{GroupId: groupId}); err != nil {
+		t.Fatalf("RestoreTaskGroup failed: %v", err)
+	}
+	groups, err := service.GetTaskGroups(ctx, &pb.GetTaskGroupsRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTaskGroups failed: %v", err)
+	}
+	if len(groups.Groups) != 1 || groups.Groups[0].TotalTasks != 2 {
+		t.Errorf("Expected the restored group with 2 tasks, got %v", groups.Groups)
+	}
+
+	// Purging removes the trash for good.
+	if _, err := service.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: tasks[1].TaskId}); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	purged, err := service.PurgeTrash(ctx, &pb.PurgeTrashRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("PurgeTrash failed: %v", err)
+	}
+	if purged.PurgedTasks != 1 || purged.PurgedGroups != 0 {
+		t.Errorf("Expected 1 purged task, got %d tasks and %d groups", purged.PurgedTasks, purged.PurgedGroups)
+	}
+	var count int
+	if err := connections.TaskDB.QueryRow("SELECT COUNT(*) FROM tasks WHERE task_id = $1", tasks[1].TaskId).Scan(&count); err != nil {
+		t.Fatalf("Failed to verify purge: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected the purged task to be gone, found %d rows", count)
+	}
+}
+
+func TestArchiveTaskGroupHidesItsTasks(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: "Archived work", TotalPomodoros: 1})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	defer RemoveTask(connections, created.Task.TaskId)
+
+	archived, err := service.ArchiveTaskGroup(ctx, &pb.ArchiveTaskGroupRequest{GroupId: groupId})
+	if err != nil {
+		t.Fatalf("ArchiveTaskGroup failed: %v", err)
+	}
+	if archived.Group.ArchivedAt == nil {
+		t.Errorf("Expected archived_at to be set")
+	}
+
+	counts := func(includeArchived bool) (groups, tasks int) {
+		t.Helper()
+		g, err := service.GetTaskGroups(ctx, &pb.GetTaskGroupsRequest{UserId: userId, IncludeArchived: includeArchived})
+		if err != nil {
+			t.Fatalf("GetTaskGroups failed: %v", err)
+		}
+		l, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId, IncludeArchived: includeArchived})
+		if err != nil {
+			t.Fatalf("GetTasks failed: %v", err)
+		}
+		return len(g.Groups), len(l.Tasks)
+	}
+	if g, n := counts(false); g != 0 || n != 0 {
+		t.Errorf("Expected archived group and task hidden by default, got %d groups and %d tasks", g, n)
+	}
+	if g, n := counts(true); g != 1 || n != 1 {
+		t.Errorf("Expected archived group and task with include_archived, got %d groups and %d tasks", g, n)
+	}
+	byGroup, err := service.GetTasks(ctx, &pb.GetTasksRequest{UserId: userId, GroupId: groupId})
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(byGroup.Tasks) != 1 {
+		t.Errorf("Expected the task when asking for its group, got %d", len(byGroup.Tasks))
+	}
+
+	unarchived, err := service.UnarchiveTaskGroup(ctx, &pb.UnarchiveTaskGroupRequest{GroupId: groupId})
+	if err != nil {
+		t.Fatalf("UnarchiveTaskGroup failed: %v", err)
+	}
+	if unarchived.Group.ArchivedAt != nil {
+		t.Errorf("Expected archived_at to be cleared")
+	}
+	if g, n := counts(false); g != 1 || n != 1 {
+		t.Errorf("Expected unarchived group and task listed, got %d groups and %d tasks", g, n)
+	}
+
+	if _, err := service.ArchiveTaskGroup(ctx, &pb.ArchiveTaskGroupRequest{GroupId: uuid.NewString()}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown group, got %v", err)
+	}
+}
+
+func TestMoveTasksAndBatchUpdate(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	fromGroup, toGroup := uuid.NewString(), uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, fromGroup, userId, "From", "Source group")
+	defer RemoveTaskGroup(connections, fromGroup)
+	seedTaskGroup(t, connections.TaskDB, toGroup, userId, "To", "Target group")
+	defer RemoveTaskGroup(connections, toGroup)
+
+	var ids []string
+	for _, name := range []string{"One", "Two"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: fromGroup, Name: name, TotalPomodoros: 1})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		ids = append(ids, created.Task.TaskId)
+	}
+	missing := uuid.NewString()
+
+	moved, err := service.MoveTasks(ctx, &pb.MoveTasksRequest{TaskIds: []string{ids[0], missing}, GroupId: toGroup})
+	if err != nil {
+		t.Fatalf("MoveTasks failed: %v", err)
+	}
+	if len(moved.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(moved.Results))
+	}
+	if r := moved.Results[0]; r.Code != int32(codes.OK) || r.Task.GroupId != toGroup {
+		t.Errorf("Expected the first task moved, got code %d (%s)", r.Code, r.Message)
+	}
+	if r := moved.Results[1]; r.Code != int32(codes.NotFound) || r.Task != nil {
+		t.Errorf("Expected NotFound for the missing task, got code %d", r.Code)
+	}
+
+	groups, err := service.GetTaskGroups(ctx, &pb.GetTaskGroupsRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTaskGroups failed: %v", err)
+	}
+	totals := map[string]int32{}
+	for _, g := range groups.Groups {
+		totals[g.GroupId] = g.TotalTasks
+	}
+	if totals[fromGroup] != 1 || totals[toGroup] != 1 {
+		t.Errorf("Expected one task in each group after the move, got %v", totals)
+	}
+
+	updated, err := service.BatchUpdateTasks(ctx, &pb.BatchUpdateTasksRequest{
+		TaskIds:  ids,
+		Status:   pb.TaskStatus_TASK_STATUS_COMPLETED,
+		Priority: pb.TaskPriority_TASK_PRIORITY_HIGH,
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdateTasks failed: %v", err)
+	}
+	for _, r := range updated.Results {
+		if r.Code != int32(codes.OK) {
+			t.Fatalf("Expected task %s updated, got code %d (%s)", r.TaskId, r.Code, r.Message)
+		}
+		if r.Task.Status != pb.TaskStatus_TASK_STATUS_COMPLETED || r.Task.Priority != pb.TaskPriority_TASK_PRIORITY_HIGH {
+			t.Errorf("Expected completed high-priority task, got %v %v", r.Task.Status, r.Task.Priority)
+		}
+		if r.Task.Name == "" {
+			t.Errorf("Expected fields outside the batch to be kept")
+		}
+	}
+
+	if _, err := service.BatchUpdateTasks(ctx, &pb.BatchUpdateTasksRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty batch, got %v", err)
+	}
+
+	// Overlapping batches listing the tasks in opposite orders, and a move within the
+	// target group, lock in the same order and all go through.
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for _, order := range [][]string{{ids[0], ids[1]}, {ids[1], ids[0]}} {
+		wg.Add(1)
+		go func(taskIDs []string) {
+			defer wg.Done()
+			_, err := service.MoveTasks(ctx, &pb.MoveTasksRequest{TaskIds: taskIDs, GroupId: toGroup})
+			errs <- err
+		}(order)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := service.MoveTask(ctx, &pb.MoveTaskRequest{TaskId: ids[0]})
+		errs <- err
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected concurrent batches to succeed, got %v", err)
+		}
+	}
+}
+
+func TestTaskTemplateRoundTrip(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	group, err := service.CreateTaskGroup(ctx, &pb.CreateTaskGroupRequest{UserId: userId, Name: "Sprint", Priority: pb.TaskGroupPriority_TASK_GROUP_PRIORITY_HIGH})
+	if err != nil {
+		t.Fatalf("CreateTaskGroup failed: %v", err)
+	}
+	defer RemoveTaskGroup(connections, group.Group.GroupId)
+
+	savedStart := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Planning", "Review"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+			UserId:         userId,
+			GroupId:        group.Group.GroupId,
+			Name:           name,
+			TotalPomodoros: int32(i + 2),
+			Deadline:       timestamppb.New(savedStart.Add(time.Duration(i*4) * 24 * time.Hour)),
+		})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+		if i == 0 {
+			if _, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: created.Task.TaskId, Title: "Agenda"}); err != nil {
+				t.Fatalf("CreateSubtask failed: %v", err)
+			}
+		}
+	}
+
+	saved, err := service.SaveTaskGroupAsTemplate(ctx, &pb.SaveTaskGroupAsTemplateRequest{GroupId: group.Group.GroupId, StartDate: timestamppb.New(savedStart)})
+	if err != nil {
+		t.Fatalf("SaveTaskGroupAsTemplate failed: %v", err)
+	}
+	defer service.DeleteTaskTemplate(ctx, &pb.DeleteTaskTemplateRequest{TemplateId: saved.Template.TemplateId})
+	if saved.Template.Name != "Sprint" || len(saved.Template.Tasks) != 2 {
+		t.Fatalf("Expected template Sprint with 2 tasks, got %q with %d", saved.Template.Name, len(saved.Template.Tasks))
+	}
+
+	listed, err := service.GetTaskTemplates(ctx, &pb.GetTaskTemplatesRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTaskTemplates failed: %v", err)
+	}
+	if len(listed.Templates) != 1 || len(listed.Templates[0].Tasks) != 2 || !slices.Equal(listed.Templates[0].Tasks[0].Subtasks, []string{"Agenda"}) {
+		t.Fatalf("Expected the saved template with its checklist, got %v", listed.Templates)
+	}
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	inst, err := service.InstantiateTaskTemplate(ctx, &pb.InstantiateTaskTemplateRequest{TemplateId: saved.Template.TemplateId, StartDate: timestamppb.New(start)})
+	if err != nil {
+		t.Fatalf("InstantiateTaskTemplate failed: %v", err)
+	}
+	defer RemoveTaskGroup(connections, inst.Group.GroupId)
+	for _, task := range inst.Tasks {
+		defer RemoveTask(connections, task.TaskId)
+	}
+
+	if inst.Group.Name != "Sprint" || inst.Group.TotalTasks != 2 || inst.Group.Priority != pb.TaskGroupPriority_TASK_GROUP_PRIORITY_HIGH {
+		t.Errorf("Unexpected group from template: %v", inst.Group)
+	}
+	if len(inst.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks from template, got %d", len(inst.Tasks))
+	}
+	if inst.Tasks[0].Name != "Planning" || inst.Tasks[0].TotalPomodoros != 2 || inst.Tasks[1].Name != "Review" {
+		t.Errorf("Expected tasks in template order with estimates, got %v", inst.Tasks)
+	}
+	if got := inst.Tasks[1].Deadline.AsTime(); !got.Equal(start.Add(4 * 24 * time.Hour)) {
+		t.Errorf("Expected the deadline shifted to the new start, got %v", got)
+	}
+	subtasks, err := service.GetSubtasks(ctx, &pb.GetSubtasksRequest{TaskId: inst.Tasks[0].TaskId})
+	if err != nil {
+		t.Fatalf("GetSubtasks failed: %v", err)
+	}
+	if len(subtasks.Subtasks) != 1 || subtasks.Subtasks[0].Title != "Agenda" || subtasks.Subtasks[0].Done {
+		t.Errorf("Expected the checklist copied undone, got %v", subtasks.Subtasks)
+	}
+}
+
+func TestEstimationAccuracy(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: "Estimate me", TotalPomodoros: 2})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	taskId := created.Task.TaskId
+	defer RemoveTask(connections, taskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", taskId)
+
+	// Re-estimate to 3, then credit 3 sessions: the original estimate of 2 stays the reference.
+	if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{TaskId: taskId, Name: "Estimate me", TotalPomodoros: 3}); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	for range 3 {
+		if err := service.CreditPomodoro(ctx, uuid.NewString(), taskId, 20*time.Minute); err != nil {
+			t.Fatalf("CreditPomodoro failed: %v", err)
+		}
+	}
+
+	history, err := service.GetTaskEstimateHistory(ctx, &pb.GetTaskEstimateHistoryRequest{TaskId: taskId})
+	if err != nil {
+		t.Fatalf("GetTaskEstimateHistory failed: %v", err)
+	}
+	if history.OriginalPomodoros != 2 || len(history.Changes) != 1 || history.Changes[0].NewPomodoros != 3 {
+		t.Errorf("Expected original 2 re-estimated to 3, got %d and %v", history.OriginalPomodoros, history.Changes)
+	}
+
+	resp, err := service.GetEstimationAccuracy(ctx, &pb.GetEstimationAccuracyRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetEstimationAccuracy failed: %v", err)
+	}
+	overall := resp.Overall
+	if overall.TaskCount != 1 || overall.EstimatedPomodoros != 2 || overall.ActualPomodoros != 3 || overall.FocusedMinutes != 60 {
+		t.Errorf("Unexpected overall accuracy: %+v", overall)
+	}
+	if overall.UnderestimatedTasks != 1 || overall.ReestimatedTasks != 1 || overall.ActualToEstimateRatio != 1.5 {
+		t.Errorf("Expected one underestimated, re-estimated task at 1.5x, got %+v", overall)
+	}
+	if len(resp.ByGroup) != 1 || resp.ByGroup[0].Key != groupId {
+		t.Errorf("Expected accuracy for the task's group, got %v", resp.ByGroup)
+	}
+
+	// completed_since follows the completion, not later edits of the completed task.
+	completedBefore := time.Now()
+	if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{
+		TaskId:         taskId,
+		Name:           "Estimate me (renamed)",
+		Status:         pb.TaskStatus_TASK_STATUS_COMPLETED,
+		TotalPomodoros: 3,
+	}); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	since := func(at time.Time) int32 {
+		t.Helper()
+		resp, err := service.GetEstimationAccuracy(ctx, &pb.GetEstimationAccuracyRequest{UserId: userId, CompletedSince: timestamppb.New(at)})
+		if err != nil {
+			t.Fatalf("GetEstimationAccuracy failed: %v", err)
+		}
+		if resp.Overall == nil {
+			return 0
+		}
+		return resp.Overall.TaskCount
+	}
+	if n := since(completedBefore); n != 0 {
+		t.Errorf("Expected a task completed before completed_since to be left out, got %d", n)
+	}
+	if n := since(completedBefore.Add(-time.Hour)); n != 1 {
+		t.Errorf("Expected the task completed after completed_since, got %d", n)
+	}
+}
+
+func TestFirstEstimateIsNotReestimate(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: "Estimate later"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	taskId := created.Task.TaskId
+	defer RemoveTask(connections, taskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", taskId)
+
+	if _, err := service.UpdateTask(ctx, &pb.UpdateTaskRequest{TaskId: taskId, Name: "Estimate later", TotalPomodoros: 2}); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	for range 2 {
+		if err := service.CreditPomodoro(ctx, uuid.NewString(), taskId, 25*time.Minute); err != nil {
+			t.Fatalf("CreditPomodoro failed: %v", err)
+		}
+	}
+
+	history, err := service.GetTaskEstimateHistory(ctx, &pb.GetTaskEstimateHistoryRequest{TaskId: taskId})
+	if err != nil {
+		t.Fatalf("GetTaskEstimateHistory failed: %v", err)
+	}
+	if history.OriginalPomodoros != 2 || len(history.Changes) != 0 {
+		t.Errorf("Expected the first estimate of 2 as the original with no changes, got %d and %v", history.OriginalPomodoros, history.Changes)
+	}
+
+	resp, err := service.GetEstimationAccuracy(ctx, &pb.GetEstimationAccuracyRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetEstimationAccuracy failed: %v", err)
+	}
+	if resp.Overall.TaskCount != 1 || resp.Overall.ReestimatedTasks != 0 {
+		t.Errorf("Expected one task that was never re-estimated, got %+v", resp.Overall)
+	}
+}
+
+func TestImportTasksDryRunAndMerge(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	existing, err := service.CreateTaskGroup(ctx, &pb.CreateTaskGroupRequest{UserId: userId, Name: "Groceries"})
+	if err != nil {
+		t.Fatalf("CreateTaskGroup failed: %v", err)
+	}
+	defer RemoveTaskGroup(connections, existing.Group.GroupId)
+
+	req := &pb.ImportTasksRequest{
+		UserId:  userId,
+		Format:  pb.ImportFormat_IMPORT_FORMAT_MARKDOWN,
+		Content: "# Groceries\n- [x] Milk\n- [ ] Bread\n  - [x] Whole grain\n  - [ ] Sliced\n# Chores\n- [ ] Laundry\n- [?] Dishes\n",
+		DryRun:  true,
+	}
+	dry, err := service.ImportTasks(ctx, req)
+	if err != nil {
+		t.Fatalf("ImportTasks dry run failed: %v", err)
+	}
+	if !dry.DryRun || dry.ImportedTasks != 3 || len(dry.Groups) != 2 || len(dry.Errors) != 1 || dry.Errors[0].Row != 8 {
+		t.Fatalf("Unexpected dry run result: %+v", dry)
+	}
+	groups, err := service.GetTaskGroups(ctx, &pb.GetTaskGroupsRequest{UserId: userId})
+	if err != nil {
+		t.Fatalf("GetTaskGroups failed: %v", err)
+	}
+	if len(groups.Groups) != 1 || groups.Groups[0].TotalTasks != 0 {
+		t.Fatalf("Expected a dry run to save nothing, got %v", groups.Groups)
+	}
+
+	req.DryRun = false
+	imported, err := service.ImportTasks(ctx, req)
+	if err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+	for _, g := range imported.Groups {
+		if !g.Existing {
+			defer RemoveTaskGroup(connections, g.Group.GroupId)
+		}
+		for _, task := range g.Tasks {
+			defer RemoveTask(connections, task.TaskId)
+		}
+	}
+	if len(imported.Groups) != 2 || !imported.Groups[0].Existing || imported.Groups[1].Existing {
+		t.Fatalf("Expected tasks merged into Groceries and a new Chores group, got %+v", imported.Groups)
+	}
+	groceries := imported.Groups[0]
+	if groceries.Group.GroupId != existing.Group.GroupId || groceries.Group.TotalTasks != 2 || groceries.Group.CompletedTasks != 1 {
+		t.Errorf("Unexpected Groceries group: %+v", groceries.Group)
+	}
+	bread := groceries.Tasks[1]
+	if bread.Name != "Bread" || bread.TotalSubtasks != 2 || bread.CompletedSubtasks != 1 || bread.Progress != 50 {
+		t.Errorf("Unexpected Bread task: %+v", bread)
+	}
+	if groceries.Tasks[0].Status != pb.TaskStatus_TASK_STATUS_COMPLETED {
+		t.Errorf("Expected Milk to be completed, got %v", groceries.Tasks[0].Status)
+	}
+}
+
+// exportStream collects the chunks ExportTasks sends.
+type exportStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks []*pb.ExportChunk
+}
+
+func (s *exportStream) Context() context.Context { return s.ctx }
+
+func (s *exportStream) Send(chunk *pb.ExportChunk) error {
+	s.chunks = append(s.chunks, chunk)
+	return nil
+}
+
+func TestExportTasks(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	group, err := service.CreateTaskGroup(ctx, &pb.CreateTaskGroupRequest{UserId: userId, Name: "Launch"})
+	if err != nil {
+		t.Fatalf("CreateTaskGroup failed: %v", err)
+	}
+	defer RemoveTaskGroup(connections, group.Group.GroupId)
+	for _, name := range []string{"Announce", "Ship"} {
+		created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+			UserId:   userId,
+			GroupId:  group.Group.GroupId,
+			Name:     name,
+			Priority: pb.TaskPriority_TASK_PRIORITY_HIGH,
+			Deadline: timestamppb.New(time.Date(2026, 12, 1, 9, 0, 0, 0, time.UTC)),
+		})
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		defer RemoveTask(connections, created.Task.TaskId)
+	}
+
+	stream := &exportStream{ctx: ctx}
+	if err := service.ExportTasks(&pb.ExportTasksRequest{UserId: userId, Format: pb.ExportFormat_EXPORT_FORMAT_ICS}, stream); err != nil {
+		t.Fatalf("ExportTasks failed: %v", err)
+	}
+	if len(stream.chunks) == 0 || !strings.HasPrefix(stream.chunks[0].ContentType, "text/calendar") ||
+		!strings.HasSuffix(stream.chunks[0].Filename, ".ics") {
+		t.Fatalf("Expected a calendar file, got %v", stream.chunks)
+	}
+	var ics strings.Builder
+	for _, chunk := range stream.chunks {
+		ics.Write(chunk.Data)
+	}
+	if n := strings.Count(ics.String(), "BEGIN:VTODO"); n != 2 {
+		t.Errorf("Expected 2 VTODOs, got %d", n)
+	}
+	if strings.Index(ics.String(), "SUMMARY:Announce") > strings.Index(ics.String(), "SUMMARY:Ship") {
+		t.Error("Expected tasks in group order")
+	}
+
+	err = service.ExportTasks(&pb.ExportTasksRequest{UserId: "nope"}, &exportStream{ctx: ctx})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad user_id, got %v", err)
+	}
+}
+
+func TestExportJSONReimports(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	group, err := service.CreateTaskGroup(ctx, &pb.CreateTaskGroupRequest{UserId: userId, Name: "Launch"})
+	if err != nil {
+		t.Fatalf("CreateTaskGroup failed: %v", err)
+	}
+	defer RemoveTaskGroup(connections, group.Group.GroupId)
+	tag, err := service.CreateTag(ctx, &pb.CreateTagRequest{UserId: userId, Name: "Marketing", Color: "#ff8800"})
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	defer connections.TaskDB.Exec("DELETE FROM tags WHERE user_id = $1", userId)
+
+	announce, err := service.CreateTask(ctx, &pb.CreateTaskRequest{
+		UserId:         userId,
+		GroupId:        group.Group.GroupId,
+		Name:           "Announce",
+		TotalPomodoros: 4,
+		Deadline:       timestamppb.New(time.Date(2026, 12, 1, 9, 0, 0, 0, time.UTC)),
+		RecurrenceRule: "FREQ=WEEKLY",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	defer RemoveTask(connections, announce.Task.TaskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_series WHERE series_id = $1", announce.Task.TaskId)
+	ship, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: group.Group.GroupId, Name: "Ship"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	defer RemoveTask(connections, ship.Task.TaskId)
+
+	if _, err := service.SetTaskTags(ctx, &pb.SetTaskTagsRequest{TaskId: announce.Task.TaskId, TagIds: []string{tag.Tag.TagId}}); err != nil {
+		t.Fatalf("SetTaskTags failed: %v", err)
+	}
+	if _, err := service.AddTaskDependency(ctx, &pb.AddTaskDependencyRequest{TaskId: ship.Task.TaskId, DependsOnId: announce.Task.TaskId}); err != nil {
+		t.Fatalf("AddTaskDependency failed: %v", err)
+	}
+	if _, err := service.CreateSubtask(ctx, &pb.CreateSubtaskRequest{TaskId: ship.Task.TaskId, Title: "Tag release"}); err != nil {
+		t.Fatalf("CreateSubtask failed: %v", err)
+	}
+	if err := service.CreditPomodoro(ctx, uuid.NewString(), announce.Task.TaskId, 25*time.Minute); err != nil {
+		t.Fatalf("CreditPomodoro failed: %v", err)
+	}
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", announce.Task.TaskId)
+
+	stream := &exportStream{ctx: ctx}
+	if err := service.ExportTasks(&pb.ExportTasksRequest{UserId: userId, Format: pb.ExportFormat_EXPORT_FORMAT_JSON}, stream); err != nil {
+		t.Fatalf("ExportTasks failed: %v", err)
+	}
+	var content strings.Builder
+	for _, chunk := range stream.chunks {
+		content.Write(chunk.Data)
+	}
+
+	// Import into another account, which has none of the groups, tags or tasks.
+	otherUser := uuid.NewString()
+	imported, err := service.ImportTasks(ctx, &pb.ImportTasksRequest{UserId: otherUser, Format: pb.ImportFormat_IMPORT_FORMAT_JSON, Content: content.String()})
+	if err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+	defer connections.TaskDB.Exec("DELETE FROM tags WHERE user_id = $1", otherUser)
+	for _, g := range imported.Groups {
+		defer RemoveTaskGroup(connections, g.Group.GroupId)
+		for _, task := range g.Tasks {
+			defer RemoveTask(connections, task.TaskId)
+			defer connections.TaskDB.Exec("DELETE FROM task_series WHERE series_id = $1", task.TaskId)
+		}
+	}
+	if len(imported.Errors) != 0 || len(imported.Groups) != 1 || len(imported.Groups[0].Tasks) != 2 {
+		t.Fatalf("Expected one group with both tasks, got %+v", imported)
+	}
+
+	gotAnnounce, gotShip := imported.Groups[0].Tasks[0], imported.Groups[0].Tasks[1]
+	if gotAnnounce.CompletedPomodoros != 1 || gotAnnounce.Progress != 25 || gotAnnounce.OriginalPomodoros != 4 {
+		t.Errorf("Expected pomodoro counts and progress kept, got %+v", gotAnnounce)
+	}
+	if gotAnnounce.RecurrenceRule != "FREQ=WEEKLY" || gotAnnounce.SeriesId != gotAnnounce.TaskId || gotAnnounce.Occurrence != 1 {
+		t.Errorf("Expected a new series headed by the imported task, got %+v", gotAnnounce)
+	}
+	if len(gotAnnounce.TagIds) != 1 || gotAnnounce.TagIds[0] == tag.Tag.TagId {
+		t.Errorf("Expected the tag recreated for the other user, got %v", gotAnnounce.TagIds)
+	}
+	if len(gotShip.DependsOn) != 1 || gotShip.DependsOn[0] != gotAnnounce.TaskId || gotShip.TotalSubtasks != 1 {
+		t.Errorf("Expected the dependency and checklist kept, got %+v", gotShip)
+	}
+	tags, err := service.GetTags(ctx, &pb.GetTagsRequest{UserId: otherUser})
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags.Tags) != 1 || tags.Tags[0].Name != "Marketing" || tags.Tags[0].Color != "#ff8800" {
+		t.Errorf("Expected the Marketing tag with its color, got %v", tags.Tags)
+	}
+}
+
+func TestExportJSONReimportKeepsAccuracy(t *testing.T) {
+	connections, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("Failed to set up test database: %v", err)
+	}
+	defer connections.Close()
+
+	service := NewService(connections)
+	ctx := context.Background()
+
+	userId := uuid.NewString()
+	groupId := uuid.NewString()
+	seedTaskGroup(t, connections.TaskDB, groupId, userId, "Test Group", "This is a test group")
+	defer RemoveTaskGroup(connections, groupId)
+
+	created, err := service.CreateTask(ctx, &pb.CreateTaskRequest{UserId: userId, GroupId: groupId, Name: "Write docs", TotalPomodoros: 2})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	taskId := created.Task.TaskId
+	defer RemoveTask(connections, taskId)
+	defer connections.TaskDB.Exec("DELETE FROM task_pomodoro_credits WHERE task_id = $1", taskId)
+	for range 2 {
+		if err := service.CreditPomodoro(ctx, uuid.NewString(), taskId, 25*time.Minute); err != nil {
+			t.Fatalf("CreditPomodoro failed: %v", err)
+		}
+	}
+
+	stream := &exportStream{ctx: ctx}
+	if err := service.ExportTasks(&pb.ExportTasksRequest{UserId: userId, Format: pb.ExportFormat_EXPORT_FORMAT_JSON}, stream); err != nil {
+		t.Fatalf("ExportTasks failed: %v", err)
+	}
+	var content strings.Builder
+	for _, chunk := range stream.chunks {
+		content.Write(chunk.Data)
+	}
+
+	otherUser := uuid.NewString()
+	imported, err := service.ImportTasks(ctx, &pb.ImportTasksRequest{UserId: otherUser, Format: pb.ImportFormat_IMPORT_FORMAT_JSON, Content: content.String()})
+	if err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+	for _, g := range imported.Groups {
+		defer RemoveTaskGroup(connections, g.Group.GroupId)
+		for _, task := range g.Tasks {
+			defer RemoveTask(connections, task.TaskId)
+		}
+	}
+
+	// The imported task has no credits, but its completed pomodoros still count.
+	resp, err := service.GetEstimationAccuracy(ctx, &pb.GetEstimationAccuracyRequest{UserId: otherUser})
+	if err != nil {
+		t.Fatalf("GetEstimationAccuracy failed: %v", err)
+	}
+	overall := resp.Overall
+	if overall == nil || overall.TaskCount != 1 || overall.EstimatedPomodoros != 2 || overall.ActualPomodoros != 2 {
+		t.Errorf("Expected the imported task estimated and done in 2 pomodoros, got %+v", overall)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+File: internal/task_management/subtask.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Checklist items (subtasks) under a task.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxSubtaskTitle bounds the title of a checklist item.
+const maxSubtaskTitle = 500
+
+const subtaskColumns = `subtask_id, task_id, title, done, position, created_at, updated_at`
+
+func scanSubtask(row rowScanner) (*pb.Subtask, error) {
+	var (
+		subtask              pb.Subtask
+		createdAt, updatedAt time.Time
+	)
+	if err := row.Scan(
+		&subtask.SubtaskId,
+		&subtask.TaskId,
+		&subtask.Title,
+		&subtask.Done,
+		&subtask.Position,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	subtask.CreatedAt = timestamppb.New(createdAt)
+	subtask.UpdatedAt = timestamppb.New(updatedAt)
+	return &subtask, nil
+}
+
+// recomputeChecklistProgress sets the progress of a task without a pomodoro estimate
+// to the percentage of its subtasks that are done. Tasks with an estimate, or without
+// subtasks, are left alone.
+func recomputeChecklistProgress(ctx context.Context, q querier, taskID string, now time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE tasks SET
+			progress = c.done * 100 / c.total,
+			updated_at = $2
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE done) AS done, COUNT(*) AS total
+			FROM subtasks WHERE task_id = $1
+		) c
+		WHERE tasks.task_id = $1 AND tasks.total_pomodoros = 0 AND c.total > 0`,
+		taskID, now)
+	return err
+}
+
+// clearChecklistProgress resets the progress of a task without a pomodoro estimate
+// once its last subtask is gone, so it does not keep the last checklist value.
+func clearChecklistProgress(ctx context.Context, q querier, taskID string, now time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE tasks SET progress = 0, updated_at = $2
+		WHERE task_id = $1 AND total_pomodoros = 0
+			AND NOT EXISTS (SELECT 1 FROM subtasks WHERE task_id = $1)`,
+		taskID, now)
+	return err
+}
+
+func (s *Service) CreateSubtask(ctx context.Context, req *pb.CreateSubtaskRequest) (*pb.CreateSubtaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if len(title) > maxSubtaskTitle {
+		return nil, status.Errorf(codes.InvalidArgument, "title must be at most %d characters", maxSubtaskTitle)
+	}
+	if req.Position < 0 {
+		return nil, status.Error(codes.InvalidArgument, "position must not be negative")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting subtask creation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create subtask")
+	}
+	defer tx.Rollback()
+
+	// Lock the parent so concurrent appends get distinct positions.
+	if _, err := getTask(ctx, tx, req.TaskId, true); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create subtask")
+	}
+
+	now := time.Now()
+	subtask, err := scanSubtask(tx.QueryRowContext(ctx, `
+		INSERT INTO subtasks (subtask_id, task_id, title, done, position, created_at, updated_at)
+		VALUES ($1, $2, $3, FALSE,
+			CASE WHEN $4 > 0 THEN $4 ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM subtasks WHERE task_id = $2) END,
+			$5, $5)
+		RETURNING `+subtaskColumns,
+		uuid.NewString(), req.TaskId, title, req.Position, now))
+	if err != nil {
+		log.Printf("Error creating subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create subtask")
+	}
+
+	task, err := s.finishSubtaskChange(ctx, tx, req.TaskId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateSubtaskResponse{Subtask: subtask, Task: task}, nil
+}
+
+func (s *Service) GetSubtasks(ctx context.Context, req *pb.GetSubtasksRequest) (*pb.GetSubtasksResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT `+subtaskColumns+`
+		FROM subtasks
+		WHERE task_id = $1
+		ORDER BY position, created_at`, req.TaskId)
+	if err != nil {
+		log.Printf("Error fetching subtasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch subtasks")
+	}
+	defer rows.Close()
+
+	var subtasks []*pb.Subtask
+	for rows.Next() {
+		subtask, err := scanSubtask(rows)
+		if err != nil {
+			log.Printf("Error scanning subtask: %v", err)
+			return nil, status.Error(codes.Internal, "failed to scan subtask")
+		}
+		subtasks = append(subtasks, subtask)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch subtasks")
+	}
+
+	return &pb.GetSubtasksResponse{Subtasks: subtasks}, nil
+}
+
+func (s *Service) UpdateSubtask(ctx context.Context, req *pb.UpdateSubtaskRequest) (*pb.UpdateSubtaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	if req.SubtaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subtask_id is required")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if len(title) > maxSubtaskTitle {
+		return nil, status.Errorf(codes.InvalidArgument, "title must be at most %d characters", maxSubtaskTitle)
+	}
+	if req.Position < 0 {
+		return nil, status.Error(codes.InvalidArgument, "position must not be negative")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting subtask update: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update subtask")
+	}
+	defer tx.Rollback()
+
+	// Lock the parent, which must be outside the trash, like CreateSubtask does.
+	if _, err := getTask(ctx, tx, req.TaskId, true); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update subtask")
+	}
+
+	now := time.Now()
+	subtask, err := scanSubtask(tx.QueryRowContext(ctx, `
+		UPDATE subtasks SET
+			title = $1,
+			done = $2,
+			position = $3,
+			updated_at = $4
+		WHERE subtask_id = $5 AND task_id = $6
+		RETURNING `+subtaskColumns,
+		title, req.Done, req.Position, now, req.SubtaskId, req.TaskId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "subtask not found")
+		}
+		log.Printf("Error updating subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update subtask")
+	}
+
+	task, err := s.finishSubtaskChange(ctx, tx, req.TaskId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateSubtaskResponse{Subtask: subtask, Task: task}, nil
+}
+
+func (s *Service) DeleteSubtask(ctx context.Context, req *pb.DeleteSubtaskRequest) (*pb.DeleteSubtaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	if req.SubtaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subtask_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting subtask deletion: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete subtask")
+	}
+	defer tx.Rollback()
+
+	// Lock the parent, which must be outside the trash, like CreateSubtask does.
+	if _, err := getTask(ctx, tx, req.TaskId, true); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete subtask")
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM subtasks WHERE subtask_id = $1 AND task_id = $2", req.SubtaskId, req.TaskId)
+	if err != nil {
+		log.Printf("Error deleting subtask: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete subtask")
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return nil, status.Error(codes.NotFound, "subtask not found")
+	}
+
+	now := time.Now()
+	if err := clearChecklistProgress(ctx, tx, req.TaskId, now); err != nil {
+		log.Printf("Error clearing checklist progress: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete subtask")
+	}
+
+	task, err := s.finishSubtaskChange(ctx, tx, req.TaskId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteSubtaskResponse{Success: true, Task: task}, nil
+}
+
+// finishSubtaskChange recomputes the parent's checklist progress, commits tx and
+// returns the updated parent task.
+func (s *Service) finishSubtaskChange(ctx context.Context, tx *sql.Tx, taskID string, now time.Time) (*pb.Task, error) {
+	if err := recomputeChecklistProgress(ctx, tx, taskID, now); err != nil {
+		log.Printf("Error recomputing checklist progress: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update task progress")
+	}
+	task, err := getTask(ctx, tx, taskID, false)
+	if err != nil {
+		log.Printf("Error fetching task after subtask change: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch task")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing subtask change: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save subtask")
+	}
+	return task, nil
+}
@@ -0,0 +1,260 @@
+/*
+File: internal/task_management/tag.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: User-scoped tags and their assignment to tasks.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxTagName    = 50
+	maxTagsOnTask = 20
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+const tagColumns = `tag_id, user_id, name, color, created_at, updated_at`
+
+func scanTag(row rowScanner) (*pb.Tag, error) {
+	var (
+		tag                  pb.Tag
+		createdAt, updatedAt time.Time
+	)
+	if err := row.Scan(&tag.TagId, &tag.UserId, &tag.Name, &tag.Color, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	tag.CreatedAt = timestamppb.New(createdAt)
+	tag.UpdatedAt = timestamppb.New(updatedAt)
+	return &tag, nil
+}
+
+// validateTag checks a tag name and color and returns the trimmed name.
+func validateTag(name, color string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", status.Error(codes.InvalidArgument, "name is required")
+	}
+	if len(name) > maxTagName {
+		return "", status.Errorf(codes.InvalidArgument, "name must be at most %d characters", maxTagName)
+	}
+	if color != "" && !tagColorPattern.MatchString(color) {
+		return "", status.Error(codes.InvalidArgument, "color must be a hex color like #ff8800")
+	}
+	return name, nil
+}
+
+func (s *Service) CreateTag(ctx context.Context, req *pb.CreateTagRequest) (*pb.CreateTagResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	name, err := validateTag(req.Name, req.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tag, err := scanTag(s.db.TaskDB.QueryRowContext(ctx, `
+		INSERT INTO tags (tag_id, user_id, name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING `+tagColumns,
+		uuid.NewString(), req.UserId, name, req.Color, now))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "a tag with this name already exists")
+		}
+		log.Printf("Error creating tag: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create tag")
+	}
+
+	return &pb.CreateTagResponse{Tag: tag}, nil
+}
+
+func (s *Service) GetTags(ctx context.Context, req *pb.GetTagsRequest) (*pb.GetTagsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT `+tagColumns+`
+		FROM tags
+		WHERE user_id = $1
+		ORDER BY LOWER(name)`, req.UserId)
+	if err != nil {
+		log.Printf("Error fetching tags: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch tags")
+	}
+	defer rows.Close()
+
+	var tags []*pb.Tag
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			log.Printf("Error scanning tag: %v", err)
+			return nil, status.Error(codes.Internal, "failed to scan tag")
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch tags")
+	}
+
+	return &pb.GetTagsResponse{Tags: tags}, nil
+}
+
+func (s *Service) UpdateTag(ctx context.Context, req *pb.UpdateTagRequest) (*pb.UpdateTagResponse, error) {
+	if req.TagId == "" {
+		return nil, status.Error(codes.InvalidArgument, "tag_id is required")
+	}
+	name, err := validateTag(req.Name, req.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := scanTag(s.db.TaskDB.QueryRowContext(ctx, `
+		UPDATE tags SET
+			name = $1,
+			color = $2,
+			updated_at = $3
+		WHERE tag_id = $4
+		RETURNING `+tagColumns,
+		name, req.Color, time.Now(), req.TagId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "tag not found")
+		}
+		if isUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "a tag with this name already exists")
+		}
+		log.Printf("Error updating tag: %v", err)
+		return nil, status.Error(codes.Internal, "failed to update tag")
+	}
+
+	return &pb.UpdateTagResponse{Tag: tag}, nil
+}
+
+// DeleteTag removes a tag and detaches it from every task.
+func (s *Service) DeleteTag(ctx context.Context, req *pb.DeleteTagRequest) (*pb.DeleteTagResponse, error) {
+	if req.TagId == "" {
+		return nil, status.Error(codes.InvalidArgument, "tag_id is required")
+	}
+
+	res, err := s.db.TaskDB.ExecContext(ctx, "DELETE FROM tags WHERE tag_id = $1", req.TagId)
+	if err != nil {
+		log.Printf("Error deleting tag: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete tag")
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return nil, status.Error(codes.NotFound, "tag not found")
+	}
+
+	return &pb.DeleteTagResponse{Success: true}, nil
+}
+
+// SetTaskTags replaces the tags of a task.
+func (s *Service) SetTaskTags(ctx context.Context, req *pb.SetTaskTagsRequest) (*pb.SetTaskTagsResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	tagIDs := slices.Compact(slices.Sorted(slices.Values(req.TagIds)))
+	if len(tagIDs) > maxTagsOnTask {
+		return nil, status.Errorf(codes.InvalidArgument, "a task can have at most %d tags", maxTagsOnTask)
+	}
+	for _, id := range tagIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "tag_ids must be valid UUIDs")
+		}
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting tag assignment: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, req.TaskId, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		log.Printf("Error fetching task for tag assignment: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+
+	// Every tag must belong to the task's owner.
+	var owned int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM tags WHERE tag_id = ANY($1) AND user_id = $2",
+		pq.Array(tagIDs), task.UserId).Scan(&owned)
+	if err != nil {
+		log.Printf("Error checking tag ownership: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+	if owned != len(tagIDs) {
+		return nil, status.Error(codes.InvalidArgument, "unknown tag in tag_ids")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_tags WHERE task_id = $1", req.TaskId); err != nil {
+		log.Printf("Error clearing task tags: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+	if len(tagIDs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO task_tags (task_id, tag_id)
+			SELECT $1, UNNEST($2::uuid[])`,
+			req.TaskId, pq.Array(tagIDs))
+		if err != nil {
+			log.Printf("Error assigning task tags: %v", err)
+			return nil, status.Error(codes.Internal, "failed to set task tags")
+		}
+	}
+
+	task, err = getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching task after tag assignment: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task tags: %v", err)
+		return nil, status.Error(codes.Internal, "failed to set task tags")
+	}
+
+	return &pb.SetTaskTagsResponse{Task: task}, nil
+}
+
+// tagFilter returns the SQL condition restricting tasks to the requested tags,
+// or "" if no tags were requested.
+func tagFilter(args *queryArgs, tagIDs []string, match pb.TagMatch) string {
+	ids := slices.Compact(slices.Sorted(slices.Values(tagIDs)))
+	if len(ids) == 0 {
+		return ""
+	}
+	if match == pb.TagMatch_TAG_MATCH_ALL {
+		return ` AND (SELECT COUNT(*) FROM task_tags tt WHERE tt.task_id = tasks.task_id AND tt.tag_id = ANY(` +
+			args.add(pq.Array(ids)) + `::uuid[])) = ` + args.add(len(ids))
+	}
+	return ` AND EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = tasks.task_id AND tt.tag_id = ANY(` +
+		args.add(pq.Array(ids)) + `::uuid[]))`
+}
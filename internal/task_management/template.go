@@ -0,0 +1,497 @@
+/*
+File: internal/task_management/template.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Task group templates: saving a group with its tasks and creating groups from it.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latrung124/Totodoro-Backend/internal/helper"
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// deadlineOffset returns how far deadline lies from start, in whole seconds.
+func deadlineOffset(deadline, start time.Time) *durationpb.Duration {
+	return durationpb.New(deadline.Sub(start).Truncate(time.Second))
+}
+
+// offsetSeconds is the stored form of an offset: seconds, or NULL for no deadline.
+func offsetSeconds(offset *durationpb.Duration) sql.NullInt64 {
+	if offset == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(offset.AsDuration() / time.Second), Valid: true}
+}
+
+func offsetFromSeconds(seconds sql.NullInt64) *durationpb.Duration {
+	if !seconds.Valid {
+		return nil
+	}
+	return durationpb.New(time.Duration(seconds.Int64) * time.Second)
+}
+
+// shiftedDeadline places an offset deadline relative to start, or returns nil without one.
+func shiftedDeadline(offset *durationpb.Duration, start time.Time) *timestamppb.Timestamp {
+	if offset == nil {
+		return nil
+	}
+	return timestamppb.New(start.Add(offset.AsDuration()))
+}
+
+const templateColumns = `template_id, user_id, name, description,
+		group_icon, group_name, group_description, group_priority,
+		group_deadline_offset_seconds, created_at`
+
+func scanTemplate(row rowScanner) (*pb.TaskTemplate, error) {
+	var (
+		template      pb.TaskTemplate
+		priorityLabel string
+		offset        sql.NullInt64
+		createdAt     time.Time
+	)
+	if err := row.Scan(
+		&template.TemplateId,
+		&template.UserId,
+		&template.Name,
+		&template.Description,
+		&template.GroupIcon,
+		&template.GroupName,
+		&template.GroupDescription,
+		&priorityLabel,
+		&offset,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+	template.GroupPriority = helper.TaskGroupPriorityDbStringToEnum(priorityLabel)
+	template.GroupDeadlineOffset = offsetFromSeconds(offset)
+	template.CreatedAt = timestamppb.New(createdAt)
+	return &template, nil
+}
+
+// loadTemplateTasks fills in the tasks of each template.
+func loadTemplateTasks(ctx context.Context, q querier, templates ...*pb.TaskTemplate) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	byID := make(map[string]*pb.TaskTemplate, len(templates))
+	ids := make([]string, len(templates))
+	for i, t := range templates {
+		byID[t.TemplateId] = t
+		ids[i] = t.TemplateId
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT template_id, icon, name, description, priority, total_pomodoros,
+			deadline_offset_seconds, subtasks
+		FROM task_template_tasks
+		WHERE template_id::text = ANY($1)
+		ORDER BY template_id, position`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			templateID    string
+			task          pb.TemplateTask
+			priorityLabel string
+			offset        sql.NullInt64
+		)
+		if err := rows.Scan(
+			&templateID,
+			&task.Icon,
+			&task.Name,
+			&task.Description,
+			&priorityLabel,
+			&task.TotalPomodoros,
+			&offset,
+			pq.Array(&task.Subtasks),
+		); err != nil {
+			return err
+		}
+		task.Priority = helper.TaskPriorityDbStringToEnum(priorityLabel)
+		task.DeadlineOffset = offsetFromSeconds(offset)
+		if t := byID[templateID]; t != nil {
+			t.Tasks = append(t.Tasks, &task)
+		}
+	}
+	return rows.Err()
+}
+
+// SaveTaskGroupAsTemplate captures a group, its tasks in order, their pomodoro estimates
+// and checklists as a template. Progress and status are not part of a template.
+func (s *Service) SaveTaskGroupAsTemplate(ctx context.Context, req *pb.SaveTaskGroupAsTemplateRequest) (*pb.SaveTaskGroupAsTemplateResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting template creation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+	defer tx.Rollback()
+
+	group, err := scanTaskGroup(tx.QueryRowContext(ctx,
+		`SELECT `+taskGroupColumns+` FROM task_groups WHERE group_id = $1 AND deleted_at IS NULL`, req.GroupId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task group not found")
+		}
+		log.Printf("Error fetching task group for template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+
+	start := group.CreatedAt.AsTime()
+	if req.StartDate != nil {
+		start = req.StartDate.AsTime()
+	}
+	name := req.Name
+	if name == "" {
+		name = group.Name
+	}
+
+	now := time.Now()
+	template := &pb.TaskTemplate{
+		TemplateId:       uuid.NewString(),
+		UserId:           group.UserId,
+		Name:             name,
+		Description:      req.Description,
+		GroupIcon:        group.Icon,
+		GroupName:        group.Name,
+		GroupDescription: group.Description,
+		GroupPriority:    group.Priority,
+		CreatedAt:        timestamppb.New(now),
+	}
+	if group.Deadline != nil {
+		template.GroupDeadlineOffset = deadlineOffset(group.Deadline.AsTime(), start)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.icon, t.name, t.description, t.priority, t.total_pomodoros, t.deadline,
+			ARRAY(SELECT s.title FROM subtasks s WHERE s.task_id = t.task_id ORDER BY s.position, s.created_at)
+		FROM tasks t
+		WHERE t.group_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.position, t.task_id::text COLLATE "C"`, req.GroupId)
+	if err != nil {
+		log.Printf("Error fetching tasks for template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+	for rows.Next() {
+		var (
+			task          pb.TemplateTask
+			priorityLabel string
+			deadline      sql.NullTime
+		)
+		if err := rows.Scan(
+			&task.Icon,
+			&task.Name,
+			&task.Description,
+			&priorityLabel,
+			&task.TotalPomodoros,
+			&deadline,
+			pq.Array(&task.Subtasks),
+		); err != nil {
+			rows.Close()
+			log.Printf("Error scanning task for template: %v", err)
+			return nil, status.Error(codes.Internal, "failed to save template")
+		}
+		task.Priority = helper.TaskPriorityDbStringToEnum(priorityLabel)
+		if deadline.Valid {
+			task.DeadlineOffset = deadlineOffset(deadline.Time, start)
+		}
+		template.Tasks = append(template.Tasks, &task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_templates (
+			template_id, user_id, name, description,
+			group_icon, group_name, group_description, group_priority,
+			group_deadline_offset_seconds, created_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		template.TemplateId,
+		template.UserId,
+		template.Name,
+		template.Description,
+		template.GroupIcon,
+		template.GroupName,
+		template.GroupDescription,
+		helper.TaskGroupPriorityDbEnumToString(template.GroupPriority),
+		offsetSeconds(template.GroupDeadlineOffset),
+		now,
+	)
+	if err != nil {
+		log.Printf("Error creating template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+	for i, task := range template.Tasks {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO task_template_tasks (
+				template_id, position, icon, name, description, priority,
+				total_pomodoros, deadline_offset_seconds, subtasks
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+			template.TemplateId,
+			i+1,
+			task.Icon,
+			task.Name,
+			task.Description,
+			helper.TaskPriorityDbEnumToString(task.Priority),
+			task.TotalPomodoros,
+			offsetSeconds(task.DeadlineOffset),
+			pq.Array(task.Subtasks),
+		)
+		if err != nil {
+			log.Printf("Error creating template task: %v", err)
+			return nil, status.Error(codes.Internal, "failed to save template")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+
+	return &pb.SaveTaskGroupAsTemplateResponse{Template: template}, nil
+}
+
+func (s *Service) GetTaskTemplates(ctx context.Context, req *pb.GetTaskTemplatesRequest) (*pb.GetTaskTemplatesResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	rows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT `+templateColumns+`
+		FROM task_templates
+		WHERE user_id = $1
+		ORDER BY created_at, template_id`, req.UserId)
+	if err != nil {
+		log.Printf("Error fetching templates: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch templates")
+	}
+	var templates []*pb.TaskTemplate
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			rows.Close()
+			log.Printf("Error scanning template: %v", err)
+			return nil, status.Error(codes.Internal, "failed to fetch templates")
+		}
+		templates = append(templates, template)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch templates")
+	}
+
+	if err := loadTemplateTasks(ctx, s.db.TaskDB, templates...); err != nil {
+		log.Printf("Error fetching template tasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to fetch templates")
+	}
+
+	return &pb.GetTaskTemplatesResponse{Templates: templates}, nil
+}
+
+func (s *Service) DeleteTaskTemplate(ctx context.Context, req *pb.DeleteTaskTemplateRequest) (*pb.DeleteTaskTemplateResponse, error) {
+	if req.TemplateId == "" {
+		return nil, status.Error(codes.InvalidArgument, "template_id is required")
+	}
+
+	res, err := s.db.TaskDB.ExecContext(ctx, "DELETE FROM task_templates WHERE template_id = $1", req.TemplateId)
+	if err != nil {
+		log.Printf("Error deleting template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete template")
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return nil, status.Error(codes.NotFound, "template not found")
+	}
+
+	return &pb.DeleteTaskTemplateResponse{Success: true}, nil
+}
+
+// InstantiateTaskTemplate creates a new group from a template, at the bottom of the
+// user's groups, with deadlines shifted to start_date. New tasks start IDLE.
+func (s *Service) InstantiateTaskTemplate(ctx context.Context, req *pb.InstantiateTaskTemplateRequest) (*pb.InstantiateTaskTemplateResponse, error) {
+	if req.TemplateId == "" {
+		return nil, status.Error(codes.InvalidArgument, "template_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting template instantiation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	defer tx.Rollback()
+
+	template, err := scanTemplate(tx.QueryRowContext(ctx,
+		`SELECT `+templateColumns+` FROM task_templates WHERE template_id = $1`, req.TemplateId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "template not found")
+		}
+		log.Printf("Error fetching template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	if err := loadTemplateTasks(ctx, tx, template); err != nil {
+		log.Printf("Error fetching template tasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	now := time.Now()
+	start := now
+	if req.StartDate != nil {
+		start = req.StartDate.AsTime()
+	}
+	groupName := req.GroupName
+	if groupName == "" {
+		groupName = template.GroupName
+	}
+
+	groupRanks := groupRankList(template.UserId)
+	if err := groupRanks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task group order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	groupPosition, err := groupRanks.appendRank(ctx, tx)
+	if err != nil {
+		log.Printf("Error ranking new task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	groupID := uuid.NewString()
+	var groupDeadline any
+	if d := shiftedDeadline(template.GroupDeadlineOffset, start); d != nil {
+		groupDeadline = d.AsTime()
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_groups (
+			group_id, user_id, icon, name, description, deadline,
+			priority, status, completed_tasks, total_tasks,
+			created_at, updated_at, position
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,0,0,$9,$9,$10)`,
+		groupID,
+		template.UserId,
+		template.GroupIcon,
+		groupName,
+		template.GroupDescription,
+		groupDeadline,
+		helper.TaskGroupPriorityDbEnumToString(template.GroupPriority),
+		helper.TaskGroupStatusDbEnumToString(pb.TaskGroupStatus_TASK_GROUP_STATUS_IDLE),
+		now,
+		groupPosition,
+	)
+	if err != nil {
+		log.Printf("Error creating task group from template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	taskRanks := taskRankList(groupID)
+	if err := taskRanks.lock(ctx, tx); err != nil {
+		log.Printf("Error locking task order: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	for _, task := range template.Tasks {
+		position, err := taskRanks.appendRank(ctx, tx)
+		if err != nil {
+			log.Printf("Error ranking new task: %v", err)
+			return nil, status.Error(codes.Internal, "failed to instantiate template")
+		}
+		var deadline any
+		if d := shiftedDeadline(task.DeadlineOffset, start); d != nil {
+			deadline = d.AsTime()
+		}
+
+		taskID := uuid.NewString()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO tasks (
+				task_id, user_id, group_id, icon, name, description,
+				priority, status, total_pomodoros, completed_pomodoros, progress,
+				deadline, created_at, updated_at, position, original_pomodoros
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,0,0,$10,$11,$11,$12,$9)`,
+			taskID,
+			template.UserId,
+			groupID,
+			task.Icon,
+			task.Name,
+			task.Description,
+			helper.TaskPriorityDbEnumToString(task.Priority),
+			helper.TaskStatusDbEnumToString(pb.TaskStatus_TASK_STATUS_IDLE),
+			task.TotalPomodoros,
+			deadline,
+			now,
+			position,
+		)
+		if err != nil {
+			log.Printf("Error creating task from template: %v", err)
+			return nil, status.Error(codes.Internal, "failed to instantiate template")
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subtasks (subtask_id, task_id, title, done, position, created_at, updated_at)
+			SELECT gen_random_uuid(), $1, s.title, FALSE, s.position, $3, $3
+			FROM unnest($2::text[]) WITH ORDINALITY AS s(title, position)`,
+			taskID, pq.Array(task.Subtasks), now); err != nil {
+			log.Printf("Error creating subtasks from template: %v", err)
+			return nil, status.Error(codes.Internal, "failed to instantiate template")
+		}
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, groupID); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	resp := &pb.InstantiateTaskTemplateResponse{}
+	resp.Group, err = scanTaskGroup(tx.QueryRowContext(ctx,
+		`SELECT `+taskGroupColumns+` FROM task_groups WHERE group_id = $1`, groupID))
+	if err != nil {
+		log.Printf("Error fetching task group created from template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE group_id = $1 ORDER BY position`, groupID)
+	if err != nil {
+		log.Printf("Error fetching tasks created from template: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			log.Printf("Error scanning task created from template: %v", err)
+			return nil, status.Error(codes.Internal, "failed to instantiate template")
+		}
+		resp.Tasks = append(resp.Tasks, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing template instantiation: %v", err)
+		return nil, status.Error(codes.Internal, "failed to instantiate template")
+	}
+
+	return resp, nil
+}
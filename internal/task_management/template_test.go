@@ -0,0 +1,41 @@
+/*
+File: internal/task_management/template_test.go
+Author: trung.la
+Date: 10/16/2026
+Description: Unit tests for template deadline offsets.
+*/
+
+package task_management
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestTemplateDeadlineOffsets(t *testing.T) {
+	saved := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	deadline := time.Date(2026, 1, 16, 17, 30, 0, 500, time.UTC)
+
+	offset := deadlineOffset(deadline, saved)
+	stored := offsetSeconds(offset)
+	if want := int64((11*24*time.Hour + 8*time.Hour + 30*time.Minute) / time.Second); !stored.Valid || stored.Int64 != want {
+		t.Fatalf("Expected %d stored seconds, got %+v", want, stored)
+	}
+
+	// The same offset lands on the same weekday and time after the new start date.
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	got := shiftedDeadline(offsetFromSeconds(stored), start).AsTime()
+	if want := time.Date(2026, 3, 13, 17, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected deadline %v, got %v", want, got)
+	}
+
+	// Deadlines before the start date shift too.
+	if got := shiftedDeadline(deadlineOffset(saved.Add(-time.Hour), saved), start).AsTime(); !got.Equal(start.Add(-time.Hour)) {
+		t.Errorf("Expected a deadline an hour before the start, got %v", got)
+	}
+
+	if offsetSeconds(nil).Valid || offsetFromSeconds(sql.NullInt64{}) != nil || shiftedDeadline(nil, start) != nil {
+		t.Errorf("Expected no deadline to stay no deadline")
+	}
+}
@@ -0,0 +1,267 @@
+/*
+File: internal/task_management/trash.go
+Author: trung.la
+Date: 10/16/2026
+Package: github.com/latrung124/Totodoro-Backend/internal/task_management
+Description: Trash for soft-deleted tasks and task groups: listing, restore and purge.
+*/
+
+package task_management
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	pb "github.com/latrung124/Totodoro-Backend/internal/proto_package/task_management_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	resp := &pb.ListTrashResponse{}
+
+	groupRows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT `+taskGroupColumns+`
+		FROM task_groups
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, group_id`, req.UserId)
+	if err != nil {
+		log.Printf("Error fetching trashed task groups: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list trash")
+	}
+	defer groupRows.Close()
+	for groupRows.Next() {
+		group, err := scanTaskGroup(groupRows)
+		if err != nil {
+			log.Printf("Error scanning trashed task group: %v", err)
+			return nil, status.Error(codes.Internal, "failed to list trash")
+		}
+		resp.Groups = append(resp.Groups, group)
+	}
+	if err := groupRows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list trash")
+	}
+
+	// Tasks whose group is in the trash are listed through the group.
+	taskRows, err := s.db.TaskDB.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM task_groups g
+			WHERE g.group_id = tasks.group_id AND g.deleted_at IS NOT NULL
+		  )
+		ORDER BY deleted_at DESC, task_id`, req.UserId)
+	if err != nil {
+		log.Printf("Error fetching trashed tasks: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list trash")
+	}
+	defer taskRows.Close()
+	for taskRows.Next() {
+		task, err := scanTask(taskRows)
+		if err != nil {
+			log.Printf("Error scanning trashed task: %v", err)
+			return nil, status.Error(codes.Internal, "failed to list trash")
+		}
+		resp.Tasks = append(resp.Tasks, task)
+	}
+	if err := taskRows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list trash")
+	}
+
+	return resp, nil
+}
+
+// RestoreTask takes a task out of the trash. A task whose group is still in the
+// trash cannot be restored on its own.
+func (s *Service) RestoreTask(ctx context.Context, req *pb.RestoreTaskRequest) (*pb.RestoreTaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task restore: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+	defer tx.Rollback()
+
+	var (
+		groupID      string
+		groupDeleted bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.group_id, EXISTS (
+			SELECT 1 FROM task_groups g WHERE g.group_id = t.group_id AND g.deleted_at IS NOT NULL
+		)
+		FROM tasks t
+		WHERE t.task_id = $1 AND t.deleted_at IS NOT NULL
+		FOR UPDATE OF t`, req.TaskId).Scan(&groupID, &groupDeleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task not found in trash")
+		}
+		log.Printf("Error fetching trashed task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+	if groupDeleted {
+		return nil, status.Error(codes.FailedPrecondition, "the task's group is in the trash; restore the group first")
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE tasks SET deleted_at = NULL, updated_at = $1 WHERE task_id = $2", now, req.TaskId); err != nil {
+		log.Printf("Error restoring task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, groupID); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+
+	task, err := getTask(ctx, tx, req.TaskId, false)
+	if err != nil {
+		log.Printf("Error fetching restored task: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task restore: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task")
+	}
+
+	return &pb.RestoreTaskResponse{Task: task}, nil
+}
+
+// RestoreTaskGroup takes a group out of the trash together with the tasks that were
+// trashed with it. Tasks trashed on their own before the group stay in the trash.
+func (s *Service) RestoreTaskGroup(ctx context.Context, req *pb.RestoreTaskGroupRequest) (*pb.RestoreTaskGroupResponse, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting task group restore: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+	defer tx.Rollback()
+
+	if err := lockGroupTasks(ctx, tx, req.GroupId); err != nil {
+		log.Printf("Error locking tasks of task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+
+	var deletedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT deleted_at FROM task_groups
+		WHERE group_id = $1 AND deleted_at IS NOT NULL
+		FOR UPDATE`, req.GroupId).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "task group not found in trash")
+		}
+		log.Printf("Error fetching trashed task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE task_groups SET deleted_at = NULL, updated_at = $1 WHERE group_id = $2", now, req.GroupId); err != nil {
+		log.Printf("Error restoring task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE tasks SET deleted_at = NULL WHERE group_id = $1 AND deleted_at = $2", req.GroupId, deletedAt); err != nil {
+		log.Printf("Error restoring tasks of task group: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+	if err := recomputeGroupCounters(ctx, tx, now, req.GroupId); err != nil {
+		log.Printf("Error recomputing task group counters: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing task group restore: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore task group")
+	}
+
+	return &pb.RestoreTaskGroupResponse{Success: true}, nil
+}
+
+// PurgeTrash permanently deletes everything in a user's trash.
+func (s *Service) PurgeTrash(ctx context.Context, req *pb.PurgeTrashRequest) (*pb.PurgeTrashResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	groups, tasks, err := s.purgeTrash(ctx, req.UserId, time.Now())
+	if err != nil {
+		log.Printf("Error purging trash of user %s: %v", req.UserId, err)
+		return nil, status.Error(codes.Internal, "failed to purge trash")
+	}
+
+	return &pb.PurgeTrashResponse{PurgedGroups: int32(groups), PurgedTasks: int32(tasks)}, nil
+}
+
+// purgeTrash permanently deletes groups and tasks trashed at or before cutoff, for one
+// user or, with an empty userID, for everyone. Subtasks, tags links and dependencies
+// go with their tasks.
+func (s *Service) purgeTrash(ctx context.Context, userID string, cutoff time.Time) (groups, tasks int64, err error) {
+	tx, err := s.db.TaskDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	// Tasks first, including any left in a purged group.
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM tasks
+		WHERE ($1 = '' OR user_id::text = $1)
+		  AND (
+			deleted_at <= $2
+			OR group_id IN (SELECT group_id FROM task_groups WHERE deleted_at <= $2)
+		  )`, userID, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	tasks, _ = res.RowsAffected()
+
+	res, err = tx.ExecContext(ctx, `
+		DELETE FROM task_groups
+		WHERE ($1 = '' OR user_id::text = $1) AND deleted_at <= $2`, userID, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	groups, _ = res.RowsAffected()
+
+	return groups, tasks, tx.Commit()
+}
+
+// RunTrashRetention periodically purges trash older than retention until ctx is cancelled.
+func (s *Service) RunTrashRetention(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			groups, tasks, err := s.purgeTrash(ctx, "", time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Error purging expired trash: %v", err)
+				continue
+			}
+			if groups > 0 || tasks > 0 {
+				log.Printf("Purged %d task groups and %d tasks from the trash", groups, tasks)
+			}
+		}
+	}
+}
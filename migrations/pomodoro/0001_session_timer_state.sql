@@ -0,0 +1,12 @@
+-- Server-side timer state for pomodoro sessions.
+-- Apply to POMODORO_DB_URL.
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS duration        INTEGER     NOT NULL DEFAULT 1500, -- planned length in seconds
+    ADD COLUMN IF NOT EXISTS paused_at       TIMESTAMPTZ NULL,                  -- set while paused
+    ADD COLUMN IF NOT EXISTS paused_duration INTEGER     NOT NULL DEFAULT 0;    -- accumulated pause in seconds
+
+-- Existing rows: derive the planned length from the stored window.
+UPDATE sessions
+SET duration = GREATEST(EXTRACT(EPOCH FROM (end_time - start_time))::INTEGER, 0)
+WHERE end_time IS NOT NULL;
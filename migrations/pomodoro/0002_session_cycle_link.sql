@@ -0,0 +1,10 @@
+-- Link each session to the one it follows in the pomodoro cycle.
+-- The unique index makes NextSession idempotent across devices.
+-- Apply to POMODORO_DB_URL.
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS previous_session_id UUID NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_previous_session_id_key
+    ON sessions (previous_session_id)
+    WHERE previous_session_id IS NOT NULL;
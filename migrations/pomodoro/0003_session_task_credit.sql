@@ -0,0 +1,9 @@
+-- Track which completed focus sessions have been credited to their task.
+-- Apply to POMODORO_DB_URL.
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS task_credited_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS sessions_uncredited_idx
+    ON sessions (last_update)
+    WHERE task_credited_at IS NULL;
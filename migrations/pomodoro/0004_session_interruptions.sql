@@ -0,0 +1,14 @@
+-- Interruptions logged against a pomodoro session.
+-- Apply to POMODORO_DB_URL.
+
+CREATE TABLE IF NOT EXISTS interruptions (
+    interruption_id UUID        PRIMARY KEY,
+    session_id      UUID        NOT NULL REFERENCES sessions (session_id) ON DELETE CASCADE,
+    kind            TEXT        NOT NULL CHECK (kind IN ('internal', 'external')),
+    note            TEXT        NOT NULL DEFAULT '',
+    paused_timer    BOOLEAN     NOT NULL DEFAULT FALSE,
+    occurred_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS interruptions_session_id_idx
+    ON interruptions (session_id, occurred_at);
@@ -0,0 +1,15 @@
+-- Post-session reflections: one per focus session.
+-- Apply to POMODORO_DB_URL.
+
+CREATE TABLE IF NOT EXISTS reflections (
+    session_id UUID        PRIMARY KEY REFERENCES sessions (session_id) ON DELETE CASCADE,
+    note       TEXT        NOT NULL DEFAULT '',
+    rating     SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    mood       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Reflections are listed per user and start-time range through sessions.
+CREATE INDEX IF NOT EXISTS sessions_user_id_start_time_idx
+    ON sessions (user_id, start_time);
@@ -0,0 +1,11 @@
+-- Ledger of focus sessions credited to tasks; one row per session makes crediting idempotent.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS task_pomodoro_credits (
+    session_id  UUID        PRIMARY KEY,
+    task_id     UUID        NOT NULL,
+    credited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_pomodoro_credits_task_id_idx
+    ON task_pomodoro_credits (task_id);
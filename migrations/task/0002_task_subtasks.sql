@@ -0,0 +1,16 @@
+-- Checklist items under a task.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS subtasks (
+    subtask_id UUID        PRIMARY KEY,
+    task_id    UUID        NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    title      TEXT        NOT NULL,
+    done       BOOLEAN     NOT NULL DEFAULT FALSE,
+    position   INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS subtasks_task_id_position_idx
+    ON subtasks (task_id, position);
+
@@ -0,0 +1,23 @@
+-- User-scoped tags and their many-to-many association with tasks.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS tags (
+    tag_id     UUID        PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    name       TEXT        NOT NULL,
+    color      TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_user_id_name_key
+    ON tags (user_id, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id UUID NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    tag_id  UUID NOT NULL REFERENCES tags (tag_id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx
+    ON task_tags (tag_id);
@@ -0,0 +1,13 @@
+-- Recurring tasks: each occurrence is a tasks row linked to its series.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS recurrence_rule  TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS recurrence_start TIMESTAMPTZ NULL,      -- DTSTART of the series
+    ADD COLUMN IF NOT EXISTS series_id        UUID        NULL,
+    ADD COLUMN IF NOT EXISTS occurrence       INTEGER     NOT NULL DEFAULT 1;
+
+-- One row per occurrence makes materializing the next occurrence idempotent.
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_series_occurrence_key
+    ON tasks (series_id, occurrence)
+    WHERE series_id IS NOT NULL;
@@ -0,0 +1,13 @@
+-- "Depends on" edges between tasks of the same user. The graph is kept acyclic by the service.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id       UUID        NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    depends_on_id UUID        NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, depends_on_id),
+    CHECK (task_id <> depends_on_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_depends_on_id_idx
+    ON task_dependencies (depends_on_id);
@@ -0,0 +1,31 @@
+-- User-defined ordering: lexicographic ranks for tasks within a group and groups per user.
+-- "C" collation makes ranks compare byte-wise, as the service computes them.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+ALTER TABLE task_groups
+    ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+-- Rank existing rows by creation time. Fixed-width hex ranks ending in 'i' sort correctly
+-- and never end in '0'.
+UPDATE tasks t SET position = r.position
+FROM (
+    SELECT task_id, LPAD(TO_HEX(ROW_NUMBER() OVER (PARTITION BY group_id ORDER BY created_at, task_id)), 6, '0') || 'i' AS position
+    FROM tasks
+) r
+WHERE t.task_id = r.task_id AND t.position = '';
+
+UPDATE task_groups g SET position = r.position
+FROM (
+    SELECT group_id, LPAD(TO_HEX(ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, group_id)), 6, '0') || 'i' AS position
+    FROM task_groups
+) r
+WHERE g.group_id = r.group_id AND g.position = '';
+
+CREATE INDEX IF NOT EXISTS tasks_group_id_position_idx
+    ON tasks (group_id, position);
+
+CREATE INDEX IF NOT EXISTS task_groups_user_id_position_idx
+    ON task_groups (user_id, position);
@@ -0,0 +1,24 @@
+-- Task group counters and status are now derived from the group's tasks.
+-- Recompute them once for existing groups; the service keeps them in sync afterwards.
+-- Apply to TASK_DB_URL.
+
+UPDATE task_groups g SET
+    completed_tasks = c.completed,
+    total_tasks = c.total,
+    status = CASE
+        WHEN c.total > 0 AND c.completed = c.total THEN 'completed'
+        WHEN c.completed > 0 OR c.in_progress > 0 THEN 'in progress'
+        WHEN c.pending > 0 THEN 'pending'
+        ELSE 'idle'
+    END
+FROM (
+    SELECT g2.group_id,
+        COUNT(t.task_id) FILTER (WHERE t.status = 'completed') AS completed,
+        COUNT(t.task_id) FILTER (WHERE t.status = 'in progress') AS in_progress,
+        COUNT(t.task_id) FILTER (WHERE t.status = 'pending') AS pending,
+        COUNT(t.task_id) AS total
+    FROM task_groups g2
+    LEFT JOIN tasks t ON t.group_id = g2.group_id
+    GROUP BY g2.group_id
+) c
+WHERE g.group_id = c.group_id;
@@ -0,0 +1,12 @@
+-- Full-text search over task names (weight A) and descriptions (weight B).
+-- The 'simple' configuration does no stemming, so prefix queries behave the same in any language.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        SETWEIGHT(TO_TSVECTOR('simple', COALESCE(name, '')), 'A') ||
+        SETWEIGHT(TO_TSVECTOR('simple', COALESCE(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS tasks_search_vector_idx
+    ON tasks USING GIN (search_vector);
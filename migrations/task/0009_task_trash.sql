@@ -0,0 +1,15 @@
+-- Soft deletion: deleted tasks and task groups stay in the trash until restored or purged.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE task_groups
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Trash listings and the retention job only look at deleted rows.
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx
+    ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS task_groups_deleted_at_idx
+    ON task_groups (deleted_at) WHERE deleted_at IS NOT NULL;
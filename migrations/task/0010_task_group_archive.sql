@@ -0,0 +1,9 @@
+-- Archived task groups: hidden, with their tasks, from lists unless asked for.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE task_groups
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- Lists exclude the (few) archived groups by default.
+CREATE INDEX IF NOT EXISTS task_groups_archived_at_idx
+    ON task_groups (archived_at) WHERE archived_at IS NOT NULL;
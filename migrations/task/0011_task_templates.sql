@@ -0,0 +1,32 @@
+-- Reusable task group templates. Deadlines are stored in seconds relative to the
+-- template's start date, so an instance can be shifted to any date.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS task_templates (
+    template_id                   UUID        PRIMARY KEY,
+    user_id                       UUID        NOT NULL,
+    name                          TEXT        NOT NULL,
+    description                   TEXT        NOT NULL DEFAULT '',
+    group_icon                    TEXT        NOT NULL DEFAULT '',
+    group_name                    TEXT        NOT NULL,
+    group_description             TEXT        NOT NULL DEFAULT '',
+    group_priority                TEXT        NOT NULL,
+    group_deadline_offset_seconds BIGINT,
+    created_at                    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_templates_user_id_idx
+    ON task_templates (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS task_template_tasks (
+    template_id             UUID    NOT NULL REFERENCES task_templates (template_id) ON DELETE CASCADE,
+    position                INTEGER NOT NULL,
+    icon                    TEXT    NOT NULL DEFAULT '',
+    name                    TEXT    NOT NULL,
+    description             TEXT    NOT NULL DEFAULT '',
+    priority                TEXT    NOT NULL,
+    total_pomodoros         INTEGER NOT NULL DEFAULT 0,
+    deadline_offset_seconds BIGINT,
+    subtasks                TEXT[]  NOT NULL DEFAULT '{}',
+    PRIMARY KEY (template_id, position)
+);
@@ -0,0 +1,24 @@
+-- Estimation tracking: the original pomodoro estimate of each task, the history of
+-- re-estimates, and the focused time of every credited session.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS original_pomodoros INTEGER NOT NULL DEFAULT 0;
+
+-- Existing tasks have no history; their current estimate is the best original we have.
+UPDATE tasks SET original_pomodoros = total_pomodoros WHERE original_pomodoros = 0;
+
+CREATE TABLE IF NOT EXISTS task_estimate_changes (
+    change_id          BIGSERIAL   PRIMARY KEY,
+    task_id            UUID        NOT NULL REFERENCES tasks (task_id) ON DELETE CASCADE,
+    previous_pomodoros INTEGER     NOT NULL,
+    new_pomodoros      INTEGER     NOT NULL,
+    changed_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_estimate_changes_task_id_idx
+    ON task_estimate_changes (task_id, changed_at);
+
+-- Sessions credited before this migration count as a pomodoro with unknown focused time.
+ALTER TABLE task_pomodoro_credits
+    ADD COLUMN IF NOT EXISTS focused_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+-- Recurring series state: the highest occurrence ever materialized, so occurrences
+-- purged from the trash are not recreated, and when the rule ran out, so the
+-- scheduler stops rescanning ended series.
+-- Apply to TASK_DB_URL.
+
+CREATE TABLE IF NOT EXISTS task_series (
+    series_id       UUID        PRIMARY KEY,
+    last_occurrence INTEGER     NOT NULL DEFAULT 1,
+    ended_at        TIMESTAMPTZ NULL
+);
+
+-- Existing series continue from the latest occurrence still on record.
+INSERT INTO task_series (series_id, last_occurrence)
+SELECT series_id, MAX(occurrence) FROM tasks
+WHERE series_id IS NOT NULL
+GROUP BY series_id
+ON CONFLICT (series_id) DO NOTHING;
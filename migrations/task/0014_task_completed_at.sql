@@ -0,0 +1,14 @@
+-- When each task was completed, so reports can filter on completion rather than on
+-- the last edit. Cleared when a task is reopened.
+-- Apply to TASK_DB_URL.
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ NULL;
+
+-- Tasks completed before this migration: their last update is the best estimate we have.
+UPDATE tasks SET completed_at = updated_at
+WHERE status = 'completed' AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_user_completed_at_idx
+    ON tasks (user_id, completed_at)
+    WHERE completed_at IS NOT NULL;